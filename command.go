@@ -0,0 +1,133 @@
+package sprout
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/aholstenson/sprout-go/internal/cli"
+	"github.com/aholstenson/sprout-go/internal/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// CommandDefinition is a command created via Command.
+type CommandDefinition = cli.Command
+
+// CommandArgs are the arguments given to a command after its flags. They can
+// be injected into the function of a command.
+type CommandArgs = cli.Args
+
+// CommandOption configures a command created via Command.
+type CommandOption func(*cli.Command)
+
+// Command creates a command that runs a one-off task within the application,
+// such as a backfill, instead of serving. The command runs after the
+// application has started, with configuration, logging and telemetry set up.
+// Workers, scheduled jobs and servers for HTTP and gRPC are not started while
+// running a command.
+//
+// run is a function that takes dependencies, optionally preceded by a
+// context.Context that is cancelled on SIGINT and SIGTERM. It can return an
+// exit code, an error, both or nothing. Errors without an exit code exit
+// with 1.
+//
+// Example:
+//
+//	sprout.Command("backfill", func(ctx context.Context, db *sql.DB, config BackfillConfig) error {
+//		// ...
+//	}, sprout.CommandDescription("Backfill missing data"), sprout.CommandConfig("BACKFILL", BackfillConfig{}))
+func Command(name string, run any, options ...CommandOption) *CommandDefinition {
+	command := &cli.Command{
+		Name: name,
+		Run:  run,
+	}
+
+	for _, option := range options {
+		option(command)
+	}
+	return command
+}
+
+// CommandDescription sets the description of a command shown in the usage.
+func CommandDescription(description string) CommandOption {
+	return func(c *cli.Command) {
+		c.Description = description
+	}
+}
+
+// CommandFlags binds flags of a command to a configuration struct read via
+// Config with the same prefix. Every field with an env tag gets a flag named
+// after the variable without the prefix, such as --batch-size for
+// BACKFILL_BATCH_SIZE. Flags that are set take precedence over the
+// environment. The flag usage can be set with a usage tag.
+func CommandFlags(prefix string, value any) CommandOption {
+	return func(c *cli.Command) {
+		c.Flags = append(c.Flags, cli.Flags{Prefix: prefix, Config: value})
+	}
+}
+
+// CommandConfig binds flags of a command to a configuration struct like
+// CommandFlags and provides the configuration to the command.
+func CommandConfig[T any](prefix string, value T) CommandOption {
+	return func(c *cli.Command) {
+		CommandFlags(prefix, value)(c)
+		CommandOptions(fx.Provide(config.Config(prefix, value)))(c)
+	}
+}
+
+// CommandOptions adds Fx options that are only used when running a command.
+func CommandOptions(options ...fx.Option) CommandOption {
+	return func(c *cli.Command) {
+		c.Options = append(c.Options, options...)
+	}
+}
+
+// Commands registers commands that can be selected on the command line when
+// the application is run via Main.
+func (s *Sprout) Commands(commands ...*CommandDefinition) *Sprout {
+	s.commands = append(s.commands, commands...)
+	return s
+}
+
+// Main parses the command line and runs the selected command with the given
+// options, exiting with the exit code of the command. Without a command, or
+// with the serve command, the application is served until it is stopped in
+// the same way as Run.
+//
+// Example:
+//
+//	sprout.New("my-service", "1.0.0").Commands(
+//		sprout.Command("backfill", runBackfill),
+//	).Main(Module)
+func (s *Sprout) Main(options ...fx.Option) {
+	os.Exit(s.execute(filepath.Base(os.Args[0]), os.Args[1:], options))
+}
+
+func (s *Sprout) execute(program string, args []string, options []fx.Option) int {
+	invocation, err := cli.Parse(program, args, s.commands, os.Stderr)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, cli.ErrUsage):
+		return 2
+	case err != nil:
+		s.logger.Error("Invalid command line", zap.Error(err))
+		return 2
+	}
+
+	if invocation.Command == nil {
+		return ExitCode(s.Run(options...))
+	}
+
+	s.serviceInfo.Command = invocation.Command.Name
+	code := cli.Execute(s.logger, func(commandOptions ...fx.Option) *fx.App {
+		return s.with(slices.Concat(options, commandOptions, []fx.Option{fx.Supply(invocation.Overrides)}), true)
+	}, invocation.Command, invocation.Args)
+
+	// Flushed after the result of the command has been logged
+	_ = s.coordinator.Flush()
+	return code
+}
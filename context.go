@@ -0,0 +1,86 @@
+package sprout
+
+import (
+	"context"
+
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/propagate"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+// Carrier stores values propagated across process boundaries, such as the
+// headers of a message. Implement it to propagate via other formats.
+type Carrier = propagation.TextMapCarrier
+
+// MapCarrier is a Carrier backed by a map[string]string.
+type MapCarrier = propagation.MapCarrier
+
+// HeaderCarrier is a Carrier backed by http.Header.
+type HeaderCarrier = propagation.HeaderCarrier
+
+// Inject writes the trace context and baggage of ctx to carrier, such as the
+// headers of a message that is about to be published.
+//
+// Example:
+//
+//	headers := map[string]string{}
+//	sprout.Inject(ctx, sprout.MapCarrier(headers))
+func Inject(ctx context.Context, carrier Carrier) {
+	propagate.Inject(ctx, carrier)
+}
+
+// Extract returns a copy of ctx with the trace context and baggage read from
+// carrier, such as the headers of a received message.
+//
+// Example:
+//
+//	ctx = sprout.Extract(ctx, sprout.MapCarrier(message.Headers))
+//	ctx, span := tracer.Start(ctx, "process")
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	return propagate.Extract(ctx, carrier)
+}
+
+// WithBaggage returns a copy of ctx with a baggage entry set. Baggage is
+// propagated to other services together with the trace context and is added
+// to loggers created via ContextLogger.
+func WithBaggage(ctx context.Context, key string, value string) (context.Context, error) {
+	return propagate.WithBaggage(ctx, key, value)
+}
+
+// Baggage returns the value of a baggage entry, or an empty string if the
+// entry is not set.
+func Baggage(ctx context.Context, key string) string {
+	return propagate.Baggage(ctx, key)
+}
+
+// WithLogFields returns a copy of ctx carrying fields that are added to
+// loggers created via ContextLogger. Unlike baggage the fields are not
+// propagated to other services.
+func WithLogFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return logging.WithFields(ctx, fields...)
+}
+
+// ContextLogger returns logger with the fields added to ctx via
+// WithLogFields and the baggage of ctx, which is added as an object named
+// baggage.
+//
+// Example:
+//
+//	ctx, _ = sprout.WithBaggage(ctx, "tenant", tenant.ID)
+//	sprout.ContextLogger(ctx, logger).Info("Processing order")
+func ContextLogger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	return logging.FromContext(ctx, logger)
+}
+
+// Detach returns a context that is not canceled when ctx is, for work that
+// outlives a request such as sending a notification after responding. The
+// trace context, baggage and log fields of ctx are kept, so spans started
+// from the returned context are part of the same trace.
+//
+// Example:
+//
+//	go sendReceipt(sprout.Detach(ctx), order)
+func Detach(ctx context.Context) context.Context {
+	return propagate.Detach(ctx)
+}
@@ -0,0 +1,49 @@
+package sprout
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/aholstenson/sprout-go/internal/crash"
+)
+
+// Go runs fn in a new goroutine. If fn panics the panic is logged with its
+// stack, the span in the context is marked as failed, telemetry is flushed
+// and the process exits with a distinct exit code.
+//
+// Example:
+//
+//	sprout.Go(ctx, "consumer", func(ctx context.Context) {
+//		// ...
+//	})
+func Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	go func() {
+		defer func() {
+			if value := recover(); value != nil {
+				crash.Report(ctx, name, value, debug.Stack())
+			}
+		}()
+
+		fn(ctx)
+	}()
+}
+
+// Recover reports a panic in the same way as Go. It must be deferred
+// directly and is intended to be used in main and in Fx lifecycle hooks, as
+// Fx runs hooks in a goroutine of its own that a Recover in main does not
+// cover.
+//
+// Example:
+//
+//	func main() {
+//		defer sprout.Recover(context.Background(), "main")
+//
+//		sprout.New("ExampleApp", "v1.0.0").With(
+//			example.Module,
+//		).Run()
+//	}
+func Recover(ctx context.Context, name string) {
+	if value := recover(); value != nil {
+		crash.Report(ctx, name, value, debug.Stack())
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/aholstenson/sprout-go"
 	"go.opentelemetry.io/otel/trace"
@@ -14,9 +15,12 @@ type Conf struct {
 }
 
 func main() {
-	sprout.New("example", "v0.0.0").With(
+	defer sprout.Recover(context.Background(), "main")
+
+	err := sprout.New("example", "v0.0.0").Run(
 		Module,
-	).Run()
+	)
+	os.Exit(sprout.ExitCode(err))
 }
 
 var Module = fx.Module(
@@ -0,0 +1,45 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/flags"
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.uber.org/fx"
+)
+
+// Flags evaluates feature flags. Flags are read from environment variables
+// named FLAG_<KEY>, such as FLAG_NEW_CHECKOUT for new-checkout, and from the
+// JSON file in FLAGS_FILE, which is reloaded when it changes. Evaluations are
+// recorded as events on the current span and counted in metrics.
+//
+// Example:
+//
+//	fx.Invoke(func(flags *sprout.Flags) {
+//		enabled := flags.Bool(ctx, "new-checkout", false, sprout.FlagAttributes{
+//			openfeature.TargetingKey: user.ID,
+//			"country":                user.Country,
+//		})
+//	})
+type Flags = flags.Flags
+
+// FlagAttributes describe the subject a flag is evaluated for and are
+// matched against the rules of flags.
+type FlagAttributes = flags.Attributes
+
+// FlagsProvider replaces the provider used by Flags with an OpenFeature
+// provider, such as one for a remote feature flag service. The constructor
+// can take dependencies and must return an openfeature.FeatureProvider.
+//
+// Example:
+//
+//	sprout.New("my-service", "1.0.0").Run(
+//		sprout.FlagsProvider(func() openfeature.FeatureProvider {
+//			return remote.NewProvider()
+//		}),
+//	)
+func FlagsProvider(constructor any) fx.Option {
+	return fx.Provide(fx.Annotate(
+		constructor,
+		fx.As(new(openfeature.FeatureProvider)),
+		fx.ResultTags(`name:"sprout:flags:provider"`),
+	))
+}
@@ -0,0 +1,49 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/grpcclient"
+	"github.com/aholstenson/sprout-go/internal/grpcserver"
+	"google.golang.org/grpc"
+)
+
+// GRPCServer is a module that serves the services registered via
+// GRPCService. The server is configured via environment variables prefixed
+// with GRPC_, is instrumented with OpenTelemetry, logs calls, recovers panics
+// in handlers and stops gracefully. The standard gRPC health service is
+// registered and reports the readiness of the application.
+//
+// Example:
+//
+//	sprout.New("my-service", "1.0.0").With(
+//		sprout.GRPCServer,
+//		fx.Provide(sprout.GRPCService(&pb.Greeter_ServiceDesc, newGreeter)),
+//	).Run()
+var GRPCServer = grpcserver.Module
+
+// GRPCService returns a function that can be used with fx.Provide to register
+// a service on GRPCServer. desc is the description generated for the service,
+// such as pb.Greeter_ServiceDesc.
+//
+// impl is either the implementation of the service or a constructor that
+// takes dependencies and returns the implementation, optionally with an error.
+func GRPCService(desc *grpc.ServiceDesc, impl any) any {
+	return grpcserver.Provide(desc, impl)
+}
+
+// GRPCClient returns a function that can be used with fx.Provide to create a
+// *grpc.ClientConn. The connection is instrumented with OpenTelemetry, logs
+// calls at debug level via a logger named grpc.client.<name> and is
+// configured via environment variables prefixed with GRPC_CLIENT_<NAME>_. It
+// is recommended to use fx.Private to keep the connection private to a
+// module.
+//
+// Example:
+//
+//	var Module = fx.Module(
+//		"payments",
+//		fx.Provide(sprout.GRPCClient("payment-api"), fx.Private),
+//		fx.Provide(pb.NewPaymentsClient, fx.Private),
+//	)
+func GRPCClient(name string) any {
+	return grpcclient.Provide(name)
+}
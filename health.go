@@ -7,3 +7,20 @@ import (
 type HealthCheck = health.Check
 
 type Health = health.Checks
+
+const (
+	// HealthGroupCritical is a group for checks that are critical for the
+	// service to function.
+	HealthGroupCritical = health.GroupCritical
+	// HealthGroupExternal is a group for checks of external dependencies.
+	HealthGroupExternal = health.GroupExternal
+)
+
+// HealthHandler serves the health probes and other endpoints of the health
+// server. It can be mounted on an application server if the standalone
+// health server has been disabled via HEALTH_SERVER_ENABLED=false.
+type HealthHandler = health.Handler
+
+// HealthStatus reports if the readiness checks of the service pass, for use
+// when exposing readiness via protocols other than HTTP.
+type HealthStatus = health.Status
@@ -0,0 +1,38 @@
+package sprout
+
+import (
+	"context"
+
+	"github.com/aholstenson/sprout-go/internal/httpserver"
+)
+
+// HTTPServer is a module that serves the routes registered via HTTPRoute. The
+// server is configured via environment variables prefixed with HTTP_, is
+// instrumented with OpenTelemetry, logs requests and is marked as not ready
+// while shutting down.
+//
+// Example:
+//
+//	sprout.New("my-service", "1.0.0").With(
+//		sprout.HTTPServer,
+//		fx.Provide(sprout.HTTPRoute("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+//			w.Write([]byte("Hello"))
+//		})),
+//	).Run()
+var HTTPServer = httpserver.Module
+
+// HTTPRoute returns a function that can be used with fx.Provide to register a
+// route on HTTPServer. The pattern uses the syntax of http.ServeMux, such as
+// "GET /users/{id}".
+//
+// handler is either a http.Handler, a handler function or a constructor that
+// takes dependencies and returns a http.Handler, optionally with an error.
+func HTTPRoute(pattern string, handler any) any {
+	return httpserver.Provide(pattern, handler)
+}
+
+// RequestID returns the ID of the request being served by HTTPServer, as
+// received in or generated for the X-Request-ID header.
+func RequestID(ctx context.Context) string {
+	return httpserver.RequestID(ctx)
+}
@@ -0,0 +1,24 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/httpclient"
+)
+
+// HTTPClient returns a function that can be used with fx.Provide to create a
+// *http.Client. The client is instrumented with OpenTelemetry, logs requests
+// at debug level via a logger named http.client.<name> and is configured via
+// environment variables prefixed with HTTP_CLIENT_<NAME>_. It is recommended
+// to use fx.Private to keep the client private to a module.
+//
+// Example:
+//
+//	var Module = fx.Module(
+//		"payments",
+//		fx.Provide(sprout.HTTPClient("payment-api"), fx.Private),
+//		fx.Invoke(func(client *http.Client) {
+//			// ...
+//		}),
+//	)
+func HTTPClient(name string) any {
+	return httpclient.Provide(name)
+}
@@ -5,3 +5,9 @@ import "os"
 func CheckIfDevelopment() bool {
 	return os.Getenv("DEVELOPMENT") == "true"
 }
+
+// CheckIfValidating returns if the application should only be validated,
+// which is requested by setting SPROUT_VALIDATE to true.
+func CheckIfValidating() bool {
+	return os.Getenv("SPROUT_VALIDATE") == "true"
+}
@@ -0,0 +1,79 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Info contains information about how the binary was built.
+type Info struct {
+	// Path is the import path of the main module
+	Path string `json:"path,omitempty"`
+	// Version is the version of the main module, empty for development builds
+	Version string `json:"version,omitempty"`
+	// Revision is the VCS revision the binary was built from
+	Revision string `json:"revision,omitempty"`
+	// Dirty is true if the working tree had local modifications
+	Dirty bool `json:"dirty"`
+	// Time is the time of the revision, used as the build time
+	Time *time.Time `json:"time,omitempty"`
+	// GoVersion is the version of Go used to build the binary
+	GoVersion string `json:"goVersion"`
+}
+
+// Read reads the build information embedded in the binary.
+func Read() Info {
+	info := Info{
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Path = build.Main.Path
+	if build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Dirty = setting.Value == "true"
+		case "vcs.time":
+			t, err := time.Parse(time.RFC3339, setting.Value)
+			if err == nil {
+				info.Time = &t
+			}
+		}
+	}
+
+	return info
+}
+
+// DefaultVersion returns a version to use when none has been specified. The
+// version of the main module is used if available, falling back to the VCS
+// revision.
+func (i Info) DefaultVersion() string {
+	if i.Version != "" {
+		return i.Version
+	}
+
+	if i.Revision == "" {
+		return "dev"
+	}
+
+	revision := i.Revision
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+
+	if i.Dirty {
+		return revision + "-dirty"
+	}
+	return revision
+}
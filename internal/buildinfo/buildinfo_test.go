@@ -0,0 +1,85 @@
+package buildinfo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/aholstenson/sprout-go/internal/buildinfo"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Build info", func() {
+	Describe("DefaultVersion", func() {
+		It("uses the module version if available", func() {
+			info := buildinfo.Info{Version: "v1.2.3", Revision: "abc"}
+			Expect(info.DefaultVersion()).To(Equal("v1.2.3"))
+		})
+
+		It("uses a short revision", func() {
+			info := buildinfo.Info{Revision: "0123456789abcdef0123"}
+			Expect(info.DefaultVersion()).To(Equal("0123456789ab"))
+		})
+
+		It("marks dirty revisions", func() {
+			info := buildinfo.Info{Revision: "abcdef", Dirty: true}
+			Expect(info.DefaultVersion()).To(Equal("abcdef-dirty"))
+		})
+
+		It("falls back to dev", func() {
+			Expect(buildinfo.Info{}.DefaultVersion()).To(Equal("dev"))
+		})
+	})
+
+	It("Read includes Go version", func() {
+		Expect(buildinfo.Read().GoVersion).To(Equal(runtime.Version()))
+	})
+
+	It("Attributes include revision", func() {
+		info := buildinfo.Info{Revision: "abcdef", GoVersion: "go1.24"}
+		attributes := info.Attributes()
+
+		keys := make([]string, 0, len(attributes))
+		for _, attr := range attributes {
+			keys = append(keys, string(attr.Key))
+		}
+		Expect(keys).To(ContainElements("vcs.ref.head.revision", "sprout.build.go_version"))
+	})
+
+	It("serves /info", func() {
+		defer http.DefaultClient.CloseIdleConnections()
+
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Provide(
+				fx.Annotated{Name: "service:name", Target: func() string { return "test" }},
+				fx.Annotated{Name: "service:version", Target: func() string { return "v1.0.0" }},
+				func() metric.MeterProvider { return noop.NewMeterProvider() },
+			),
+			buildinfo.Module(buildinfo.Info{Revision: "abcdef", GoVersion: "go1.24"}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Get("http://localhost:8088/info")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		var body map[string]any
+		Expect(json.NewDecoder(res.Body).Decode(&body)).To(Succeed())
+		Expect(body).To(HaveKeyWithValue("name", "test"))
+		Expect(body).To(HaveKeyWithValue("version", "v1.0.0"))
+		Expect(body["build"]).To(HaveKeyWithValue("revision", "abcdef"))
+	})
+})
@@ -0,0 +1,92 @@
+package buildinfo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.uber.org/fx"
+)
+
+// Module reports the build information as resource attributes, as a
+// build_info metric and on the /info endpoint of the health server.
+func Module(info Info) fx.Option {
+	return fx.Module(
+		"sprout:buildinfo",
+		fx.Supply(info),
+		fx.Provide(fx.Annotate(
+			info.Attributes,
+			fx.ResultTags(`group:"otel:resource,flatten"`),
+		)),
+		fx.Invoke(register),
+	)
+}
+
+// Attributes returns the build information as resource attributes.
+func (i Info) Attributes() []attribute.KeyValue {
+	attributes := []attribute.KeyValue{
+		attribute.String("sprout.build.go_version", i.GoVersion),
+		attribute.Bool("sprout.build.dirty", i.Dirty),
+	}
+
+	if i.Revision != "" {
+		attributes = append(attributes, semconv.VCSRefHeadRevision(i.Revision))
+	}
+
+	if i.Time != nil {
+		attributes = append(attributes, attribute.String("sprout.build.time", i.Time.Format(time.RFC3339)))
+	}
+
+	return attributes
+}
+
+type registerParams struct {
+	fx.In
+
+	Info           Info
+	ServiceName    string `name:"service:name"`
+	ServiceVersion string `name:"service:version"`
+	Routes         health.Routes
+	MeterProvider  metric.MeterProvider
+}
+
+func register(p registerParams) error {
+	info := p.Info
+	_, err := p.MeterProvider.Meter("sprout/buildinfo").Int64ObservableGauge(
+		"build_info",
+		metric.WithDescription("Information about the build of the service, always 1."),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(1, metric.WithAttributes(
+				attribute.String("version", p.ServiceVersion),
+				attribute.String("revision", info.Revision),
+				attribute.Bool("dirty", info.Dirty),
+				attribute.String("go_version", info.GoVersion),
+			))
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	response := struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Build   Info   `json:"build"`
+	}{
+		Name:    p.ServiceName,
+		Version: p.ServiceVersion,
+		Build:   info,
+	}
+
+	p.Routes.AddRoute("GET /info", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	return nil
+}
@@ -0,0 +1,191 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/aholstenson/sprout-go/internal/cli"
+	"github.com/aholstenson/sprout-go/internal/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/zap/zaptest"
+)
+
+type backfillConfig struct {
+	BatchSize int  `env:"BATCH_SIZE" envDefault:"100" usage:"number of rows per batch"`
+	DryRun    bool `env:"DRY_RUN"`
+
+	Source sourceConfig `envPrefix:"SOURCE_"`
+}
+
+type sourceConfig struct {
+	Table string `env:"TABLE"`
+}
+
+var _ = Describe("Commands", func() {
+	var output *bytes.Buffer
+	var commands []*cli.Command
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		commands = []*cli.Command{
+			{
+				Name:        "backfill",
+				Description: "Backfill missing data",
+				Run:         func() {},
+				Flags: []cli.Flags{
+					{Prefix: "BACKFILL", Config: backfillConfig{}},
+				},
+			},
+		}
+	})
+
+	Describe("parsing", func() {
+		It("serves without a command", func() {
+			invocation, err := cli.Parse("app", nil, commands, output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(invocation.Command).To(BeNil())
+
+			invocation, err = cli.Parse("app", []string{"serve"}, commands, output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(invocation.Command).To(BeNil())
+		})
+
+		It("prints usage when asked for help", func() {
+			_, err := cli.Parse("app", []string{"--help"}, commands, output)
+			Expect(err).To(MatchError(flag.ErrHelp))
+			Expect(output.String()).To(ContainSubstring("Usage: app [command]"))
+			Expect(output.String()).To(ContainSubstring("backfill"))
+			Expect(output.String()).To(ContainSubstring("Backfill missing data"))
+		})
+
+		It("prints the flags of a command", func() {
+			_, err := cli.Parse("app", []string{"backfill", "-h"}, commands, output)
+			Expect(err).To(MatchError(flag.ErrHelp))
+			Expect(output.String()).To(ContainSubstring("-batch-size"))
+			Expect(output.String()).To(ContainSubstring("number of rows per batch"))
+			Expect(output.String()).To(ContainSubstring("-source-table"))
+		})
+
+		It("rejects unknown commands", func() {
+			_, err := cli.Parse("app", []string{"unknown"}, commands, output)
+			Expect(err).To(MatchError(cli.ErrUsage))
+			Expect(output.String()).To(ContainSubstring("unknown command unknown"))
+		})
+
+		It("rejects unknown flags", func() {
+			_, err := cli.Parse("app", []string{"backfill", "--unknown"}, commands, output)
+			Expect(err).To(MatchError(cli.ErrUsage))
+		})
+
+		It("overrides the environment from flags", func() {
+			t := GinkgoT()
+			t.Setenv("BACKFILL_BATCH_SIZE", "50")
+
+			invocation, err := cli.Parse("app", []string{"backfill", "--batch-size", "10", "--dry-run", "--source-table=users", "extra"}, commands, output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(invocation.Command.Name).To(Equal("backfill"))
+			Expect(invocation.Args).To(Equal(cli.Args{"extra"}))
+
+			Expect(invocation.Overrides).To(Equal(config.Overrides{
+				"BACKFILL_BATCH_SIZE":   "10",
+				"BACKFILL_DRY_RUN":      "true",
+				"BACKFILL_SOURCE_TABLE": "users",
+			}))
+
+			// The environment of the process is left alone
+			Expect(os.Getenv("BACKFILL_BATCH_SIZE")).To(Equal("50"))
+		})
+
+		It("does not override the environment for flags that are not set", func() {
+			invocation, err := cli.Parse("app", []string{"backfill"}, commands, output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(invocation.Overrides).To(BeEmpty())
+		})
+
+		It("reads configuration with the overrides", func() {
+			t := GinkgoT()
+			t.Setenv("BACKFILL_BATCH_SIZE", "50")
+			t.Setenv("BACKFILL_SOURCE_TABLE", "orders")
+
+			invocation, err := cli.Parse("app", []string{"backfill", "--batch-size", "10"}, commands, output)
+			Expect(err).ToNot(HaveOccurred())
+
+			var cfg backfillConfig
+			app := fx.New(
+				fx.NopLogger,
+				fx.Supply(invocation.Overrides),
+				fx.Provide(config.Config("BACKFILL", backfillConfig{})),
+				fx.Populate(&cfg),
+			)
+			Expect(app.Err()).ToNot(HaveOccurred())
+			Expect(cfg.BatchSize).To(Equal(10))
+			Expect(cfg.Source.Table).To(Equal("orders"))
+		})
+	})
+
+	Describe("running", func() {
+		newApp := func(options ...fx.Option) *fx.App {
+			return fx.New(
+				fx.NopLogger,
+				fx.Supply("dependency"),
+				fx.Options(options...),
+			)
+		}
+
+		execute := func(run any) int {
+			command := &cli.Command{Name: "test", Run: run}
+			return cli.Execute(zaptest.NewLogger(GinkgoT()), newApp, command, cli.Args{"a", "b"})
+		}
+
+		It("injects dependencies, the context and arguments", func() {
+			var value string
+			var args cli.Args
+			code := execute(func(ctx context.Context, dependency string, a cli.Args) {
+				Expect(ctx).ToNot(BeNil())
+				value = dependency
+				args = a
+			})
+			Expect(code).To(Equal(0))
+			Expect(value).To(Equal("dependency"))
+			Expect(args).To(Equal(cli.Args{"a", "b"}))
+		})
+
+		It("runs after the application has started", func() {
+			started := false
+			code := cli.Execute(zaptest.NewLogger(GinkgoT()), func(options ...fx.Option) *fx.App {
+				return newApp(append(options, fx.Invoke(func(lifecycle fx.Lifecycle) {
+					lifecycle.Append(fx.StartHook(func() {
+						started = true
+					}))
+				}))...)
+			}, &cli.Command{Name: "test", Run: func() int {
+				Expect(started).To(BeTrue())
+				return 0
+			}}, nil)
+			Expect(code).To(Equal(0))
+		})
+
+		It("exits with the returned exit code", func() {
+			Expect(execute(func() int { return 3 })).To(Equal(3))
+			Expect(execute(func() (int, error) { return 4, errors.New("failed") })).To(Equal(4))
+		})
+
+		It("exits with 1 if an error is returned", func() {
+			Expect(execute(func() error { return errors.New("failed") })).To(Equal(1))
+		})
+
+		It("exits with 1 if dependencies are missing", func() {
+			Expect(execute(func(missing int) {})).To(Equal(1))
+		})
+
+		It("rejects unsupported results", func() {
+			Expect(execute(func() string { return "" })).To(Equal(1))
+			Expect(execute("not a function")).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,280 @@
+// Package cli runs commands of an application, such as serving requests or
+// one-off tasks, based on the command line.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ServeCommand is the name of the default command, which runs the service
+// until it is stopped.
+const ServeCommand = "serve"
+
+// Command is a task that runs within the application instead of serving.
+type Command struct {
+	Name        string
+	Description string
+	// Run is a function that takes dependencies, optionally preceded by a
+	// context.Context, and returns an exit code, an error, both or nothing
+	Run any
+	// Flags binds flags of the command to configuration structs
+	Flags []Flags
+	// Options are additional Fx options used when running the command
+	Options []fx.Option
+}
+
+// Args are the arguments of a command that remain after parsing flags.
+type Args []string
+
+// Invocation is a command to run, as parsed from the command line.
+type Invocation struct {
+	// Command to run, nil when serving
+	Command *Command
+	Args    Args
+	// Overrides are the environment variables set via flags, to be supplied
+	// to the application running the command
+	Overrides config.Overrides
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	intType     = reflect.TypeOf(0)
+)
+
+// ErrUsage is returned by Parse when the command line is invalid. The
+// problem and usage have already been written to the output.
+var ErrUsage = errors.New("invalid usage")
+
+// Parse determines the command to run from the arguments of the program,
+// excluding the program name. Usage is written to output if requested or
+// the arguments are invalid, in which case flag.ErrHelp or ErrUsage is
+// returned.
+func Parse(program string, args []string, commands []*Command, output io.Writer) (*Invocation, error) {
+	if len(args) == 0 || args[0] == ServeCommand {
+		if len(args) > 1 {
+			return nil, usageError(output, program, commands, "serve does not take arguments")
+		}
+		return &Invocation{}, nil
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(output, program, commands)
+		return nil, flag.ErrHelp
+	}
+
+	var command *Command
+	for _, c := range commands {
+		if c.Name == args[0] {
+			command = c
+			break
+		}
+	}
+
+	if command == nil {
+		return nil, usageError(output, program, commands, "unknown command "+args[0])
+	}
+
+	fs := flag.NewFlagSet(program+" "+command.Name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(output, "Usage: %s %s [flags] [args]\n", program, command.Name)
+		if command.Description != "" {
+			_, _ = fmt.Fprintf(output, "\n%s\n", command.Description)
+		}
+		_, _ = fmt.Fprintln(output, "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	for _, flags := range command.Flags {
+		if err := flags.register(fs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, ErrUsage
+	}
+
+	return &Invocation{
+		Command:   command,
+		Args:      fs.Args(),
+		Overrides: overrides(fs),
+	}, nil
+}
+
+func usageError(output io.Writer, program string, commands []*Command, message string) error {
+	_, _ = fmt.Fprintf(output, "%s\n\n", message)
+	printUsage(output, program, commands)
+	return ErrUsage
+}
+
+func printUsage(output io.Writer, program string, commands []*Command) {
+	_, _ = fmt.Fprintf(output, "Usage: %s [command] [flags] [args]\n\nCommands:\n", program)
+
+	w := tabwriter.NewWriter(output, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", ServeCommand, "Run the service, the default if no command is given")
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", c.Name, c.Description)
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintf(output, "\nRun '%s <command> -h' for the flags of a command.\n", program)
+}
+
+// Prepare creates an option that resolves the dependencies of the command
+// and a function that runs it with the resolved dependencies once the
+// application has started.
+func (c *Command) Prepare(args Args) (fx.Option, func(ctx context.Context) (int, error), error) {
+	fn := reflect.ValueOf(c.Run)
+	if c.Run == nil || fn.Kind() != reflect.Func {
+		return nil, nil, fmt.Errorf("command %s must be a function, got %T", c.Name, c.Run)
+	}
+
+	fnType := fn.Type()
+	if err := validateResults(fnType); err != nil {
+		return nil, nil, fmt.Errorf("command %s %w", c.Name, err)
+	}
+
+	takesContext := fnType.NumIn() > 0 && fnType.In(0) == contextType
+	var in []reflect.Type
+	for i := range fnType.NumIn() {
+		if i == 0 && takesContext {
+			continue
+		}
+		in = append(in, fnType.In(i))
+	}
+
+	var resolved []reflect.Value
+	invoke := reflect.MakeFunc(reflect.FuncOf(in, nil, fnType.IsVariadic()), func(values []reflect.Value) []reflect.Value {
+		resolved = values
+		return nil
+	})
+
+	run := func(ctx context.Context) (int, error) {
+		values := resolved
+		if takesContext {
+			values = append([]reflect.Value{reflect.ValueOf(ctx)}, values...)
+		}
+
+		var results []reflect.Value
+		if fnType.IsVariadic() {
+			results = fn.CallSlice(values)
+		} else {
+			results = fn.Call(values)
+		}
+		return exitCode(results)
+	}
+
+	options := fx.Options(
+		fx.Supply(args),
+		fx.Options(c.Options...),
+		fx.Invoke(invoke.Interface()),
+	)
+	return options, run, nil
+}
+
+func validateResults(fnType reflect.Type) error {
+	switch fnType.NumOut() {
+	case 0:
+		return nil
+	case 1:
+		if fnType.Out(0) == errorType || fnType.Out(0) == intType {
+			return nil
+		}
+	case 2:
+		if fnType.Out(0) == intType && fnType.Out(1) == errorType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must return an exit code, an error, both or nothing, got %s", fnType)
+}
+
+// exitCode converts the results of a command into an exit code, using 1 for
+// errors without an exit code.
+func exitCode(results []reflect.Value) (int, error) {
+	code := 0
+	var err error
+	for _, result := range results {
+		switch result.Type() {
+		case intType:
+			code = int(result.Int())
+		case errorType:
+			if !result.IsNil() {
+				err = result.Interface().(error)
+			}
+		}
+	}
+
+	if err != nil && code == 0 {
+		code = 1
+	}
+	return code, err
+}
+
+// Execute runs a command within an application created by newApp, which is
+// started before and stopped after the command runs. The context passed to
+// the command is cancelled on SIGINT and SIGTERM. Returns the exit code of
+// the command.
+func Execute(logger *zap.Logger, newApp func(options ...fx.Option) *fx.App, command *Command, args Args) int {
+	option, run, err := command.Prepare(args)
+	if err != nil {
+		logger.Error("Invalid command", zap.String("command", command.Name), zap.Error(err))
+		return 1
+	}
+
+	app := newApp(option)
+	if err := app.Err(); err != nil {
+		// Already logged by Fx
+		return 1
+	}
+
+	startCtx, cancel := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		logger.Error("Unable to start application", zap.String("command", command.Name), zap.Error(err))
+		return 1
+	}
+
+	logger.Info("Running command", zap.String("command", command.Name))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	start := time.Now()
+	code, err := run(ctx)
+	stop()
+
+	if err != nil {
+		logger.Error("Command failed", zap.String("command", command.Name), zap.Int("exitCode", code), zap.Error(err))
+	} else {
+		logger.Info("Command completed", zap.String("command", command.Name), zap.Int("exitCode", code), zap.Duration("duration", time.Since(start)))
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancel()
+	if err := app.Stop(stopCtx); err != nil {
+		logger.Error("Unable to stop application", zap.String("command", command.Name), zap.Error(err))
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+)
+
+// Flags binds command line flags to the fields of a configuration struct.
+// Every field with an env tag gets a flag named after the variable without
+// the prefix, such as --batch-size for BACKFILL_BATCH_SIZE. Flags that are
+// set override the environment of the application running the command.
+type Flags struct {
+	Prefix string
+	Config any
+}
+
+// envValue is a flag that overrides an environment variable once parsed.
+type envValue struct {
+	key    string
+	isBool bool
+
+	value string
+}
+
+func (v *envValue) String() string {
+	return v.value
+}
+
+func (v *envValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *envValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// register adds flags for the fields of the configuration to fs.
+func (f Flags) register(fs *flag.FlagSet) error {
+	t := reflect.TypeOf(f.Config)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("flags must be bound to a struct, got %T", f.Config)
+	}
+
+	prefix := f.Prefix
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	return registerStruct(fs, t, prefix, "")
+}
+
+func registerStruct(fs *flag.FlagSet, t reflect.Type, envPrefix string, flagPrefix string) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if nested, ok := field.Tag.Lookup("envPrefix"); ok && field.Type.Kind() == reflect.Struct {
+			err := registerStruct(fs, field.Type, envPrefix+nested, flagPrefix+nested)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		value := &envValue{
+			key:    envPrefix + name,
+			isBool: field.Type.Kind() == reflect.Bool,
+			value:  field.Tag.Get("envDefault"),
+		}
+
+		usage := field.Tag.Get("usage")
+		if usage == "" {
+			usage = "sets " + value.key
+		}
+
+		flagName := strings.ReplaceAll(strings.ToLower(flagPrefix+name), "_", "-")
+		if fs.Lookup(flagName) != nil {
+			return fmt.Errorf("flag --%s is defined more than once", flagName)
+		}
+
+		fs.Var(value, flagName, usage)
+	}
+
+	return nil
+}
+
+// overrides returns the environment variables of the flags that were set.
+func overrides(fs *flag.FlagSet) config.Overrides {
+	result := config.Overrides{}
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := f.Value.(*envValue); ok {
+			result[value.key] = value.value
+		}
+	})
+	return result
+}
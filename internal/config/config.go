@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"maps"
+	"os"
 	"reflect"
 
 	"github.com/aholstenson/sprout-go/internal/logging"
@@ -14,49 +16,86 @@ type In struct {
 	fx.In
 
 	Logger *zap.Logger `optional:"true"`
+	// Validation is available when the application is being validated
+	Validation *Validation `optional:"true"`
+	// Overrides is available when values have been set for the application,
+	// such as via the flags of a command
+	Overrides Overrides `optional:"true"`
 }
 
+// Overrides are values that take precedence over the environment variables
+// of the process, keyed by the name of the variable. They only apply to the
+// application they are supplied to.
+type Overrides map[string]string
+
 // Config will read configuration from the environment and provide the
 // specified type to the application.
 func Config[T any](prefix string, value T) any {
+	return func(in In) (T, error) {
+		return Load(prefix, value, in)
+	}
+}
+
+// Load reads configuration with the given prefix from the environment into
+// a copy of value. It is used by Config and by constructors that need to
+// read configuration with a prefix only known when they are called.
+func Load[T any](prefix string, value T, in In) (T, error) {
 	if prefix != "" {
 		prefix += "_"
 	}
 
-	return func(in In) (T, error) {
-		config := value
+	config := value
 
-		logger := in.Logger
-		if logger == nil {
-			// No logger provided, use the default logger
-			logger = logging.CreateLogger(zap.L(), []string{"config"})
-		}
+	logger := in.Logger
+	if logger == nil {
+		// No logger provided, use the default logger
+		logger = logging.CreateLogger(zap.L(), []string{"config"})
+	}
 
-		opts := env.Options{
-			Prefix: prefix,
-			OnSet:  logFunc(logger),
-		}
+	opts := env.Options{
+		Prefix: prefix,
+		OnSet:  logFunc(logger),
+	}
 
-		var err error
-		if reflect.TypeOf(config).Kind() == reflect.Ptr {
-			err = env.ParseWithOptions(config, opts)
-		} else {
-			err = env.ParseWithOptions(&config, opts)
-		}
+	if len(in.Overrides) > 0 {
+		opts.Environment = Environment(in.Overrides)
+	}
+
+	var err error
+	if reflect.TypeOf(config).Kind() == reflect.Ptr {
+		err = env.ParseWithOptions(config, opts)
+	} else {
+		err = env.ParseWithOptions(&config, opts)
+	}
 
-		var aggregateError env.AggregateError
-		if errors.As(err, &aggregateError) {
-			for _, err := range aggregateError.Errors {
-				logError(logger, err)
-			}
+	var aggregateError env.AggregateError
+	if errors.As(err, &aggregateError) {
+		for _, err := range aggregateError.Errors {
+			logError(logger, err)
+		}
 
-			return config, errors.New("failed to load configuration")
-		} else if err != nil {
-			return config, err
+		configErr := newError(prefix, aggregateError.Errors)
+		if in.Validation != nil {
+			// Keep resolving so every configuration error is reported
+			in.Validation.Add(configErr.Errors...)
+			return config, nil
 		}
 
-		return config, nil
+		return config, configErr
+	} else if err != nil {
+		return config, err
 	}
+
+	return config, nil
+}
+
+// Environment returns the environment variables of the process with the
+// overrides applied. It is used to read variables that can not be bound to
+// a struct, such as those with names only known at runtime.
+func Environment(overrides Overrides) map[string]string {
+	environment := env.ToMap(os.Environ())
+	maps.Copy(environment, overrides)
+	return environment
 }
 
 func logFunc(logger *zap.Logger) func(tag string, value interface{}, isDefault bool) {
@@ -1,6 +1,8 @@
 package config_test
 
 import (
+	"errors"
+
 	"github.com/aholstenson/sprout-go/internal/config"
 	"github.com/aholstenson/sprout-go/internal/logging"
 	. "github.com/onsi/ginkgo/v2"
@@ -15,6 +17,11 @@ type Config struct {
 	Port int    `env:"PORT" envDefault:"8080"`
 }
 
+type RequiredConfig struct {
+	Port int    `env:"PORT" envDefault:"8080"`
+	Name string `env:"NAME,required"`
+}
+
 var _ = Describe("Config", func() {
 	It("should be able to provide config", func() {
 		var readConfig Config
@@ -69,4 +76,53 @@ var _ = Describe("Config", func() {
 		Expect(readConfig.Host).To(Equal("test"))
 		Expect(readConfig.Port).To(Equal(1234))
 	})
+
+	It("collects errors when validating", func() {
+		t := GinkgoT()
+		t.Setenv("TEST_PORT", "invalid")
+
+		validation := &config.Validation{}
+		var readConfig RequiredConfig
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(validation),
+			fx.Provide(config.Config("TEST", RequiredConfig{})),
+			fx.Populate(&readConfig),
+		)
+		Expect(app.Err()).ToNot(HaveOccurred())
+
+		errs := validation.Errors()
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Error()).To(ContainSubstring("config TEST"))
+		Expect(errs[0].Error()).To(ContainSubstring("Port"))
+		Expect(errs[1].Error()).To(ContainSubstring("TEST_NAME"))
+	})
+
+	It("fails on errors when not validating", func() {
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Provide(config.Config("TEST", RequiredConfig{})),
+			fx.Invoke(func(RequiredConfig) {}),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("failed to load configuration")))
+
+		var configErr *config.Error
+		Expect(errors.As(app.Err(), &configErr)).To(BeTrue())
+		Expect(configErr.Prefix).To(Equal("TEST"))
+		Expect(configErr.Errors).To(HaveLen(1))
+		Expect(configErr.Errors[0].Error()).To(ContainSubstring("TEST_NAME"))
+	})
+
+	It("can load config directly", func() {
+		GinkgoT().Setenv("TEST_PORT", "9090")
+
+		cfg, err := config.Load("TEST", Config{}, config.In{
+			Logger: zaptest.NewLogger(GinkgoT()),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Host).To(Equal("localhost"))
+		Expect(cfg.Port).To(Equal(9090))
+	})
 })
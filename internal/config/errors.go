@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is returned when configuration could not be read from the
+// environment. Errors contains every problem found.
+type Error struct {
+	Prefix string
+	Errors []error
+}
+
+func newError(prefix string, errs []error) *Error {
+	prefix = strings.TrimSuffix(prefix, "_")
+
+	wrapped := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if prefix != "" {
+			// Parse errors only name the field, so include the prefix
+			err = fmt.Errorf("config %s: %w", prefix, err)
+		}
+		wrapped = append(wrapped, err)
+	}
+
+	return &Error{
+		Prefix: prefix,
+		Errors: wrapped,
+	}
+}
+
+func (e *Error) Error() string {
+	return "failed to load configuration"
+}
+
+func (e *Error) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,27 @@
+package config
+
+import "sync"
+
+// Validation collects configuration errors while the application is being
+// validated. When it is available to Config the errors are recorded instead
+// of failing, so that every invalid value can be reported at once.
+type Validation struct {
+	mu     sync.Mutex
+	errors []error
+}
+
+// Add records errors found while validating.
+func (v *Validation) Add(errs ...error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.errors = append(v.errors, errs...)
+}
+
+// Errors returns the errors recorded so far.
+func (v *Validation) Errors() []error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return append([]error(nil), v.errors...)
+}
@@ -0,0 +1,106 @@
+// Package constructor adapts user supplied functions into constructors that
+// can be used with Fx.
+package constructor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Wrap creates a constructor for use with fx.Provide that calls build with a
+// value of type F, such as a function or an interface. fn is either a value
+// of type F, or a constructor that takes dependencies and returns F,
+// optionally with an error. The returned constructor takes the same
+// dependencies as fn and returns the result of build and an error.
+func Wrap[F any, T any](fn any, build func(F) T) (any, error) {
+	if f, ok := convert[F](fn); ok {
+		return func() (T, error) {
+			return build(f), nil
+		}, nil
+	}
+
+	return Constructor(fn, build)
+}
+
+// Constructor is like Wrap but requires fn to be a constructor, for use when
+// a value of type F could itself be a function.
+func Constructor[F any, T any](fn any, build func(F) T) (any, error) {
+	funcType := reflect.TypeFor[F]()
+	resultType := reflect.TypeFor[T]()
+
+	fnValue := reflect.ValueOf(fn)
+	if fn == nil || fnValue.Kind() != reflect.Func {
+		return nil, fmt.Errorf("expected %s or a constructor returning it, got %T", funcType, fn)
+	}
+
+	fnType := fnValue.Type()
+	returnsError := fnType.NumOut() == 2 && fnType.Out(1) == errorType
+	if fnType.NumOut() == 0 || fnType.NumOut() > 2 || (fnType.NumOut() == 2 && !returnsError) {
+		return nil, fmt.Errorf("constructor must return %s and optionally an error, got %s", funcType, fnType)
+	}
+
+	if !fnType.Out(0).ConvertibleTo(funcType) {
+		return nil, fmt.Errorf("constructor must return %s, got %s", funcType, fnType.Out(0))
+	}
+
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+
+	constructorType := reflect.FuncOf(in, []reflect.Type{resultType, errorType}, fnType.IsVariadic())
+	constructor := reflect.MakeFunc(constructorType, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if fnType.IsVariadic() {
+			results = fnValue.CallSlice(args)
+		} else {
+			results = fnValue.Call(args)
+		}
+
+		if returnsError && !results[1].IsNil() {
+			return []reflect.Value{reflect.Zero(resultType), results[1]}
+		}
+
+		if isNil(results[0]) {
+			err := fmt.Errorf("constructor returned a nil %s", funcType)
+			return []reflect.Value{reflect.Zero(resultType), reflect.ValueOf(&err).Elem()}
+		}
+
+		f := results[0].Convert(funcType).Interface().(F)
+		return []reflect.Value{
+			reflect.ValueOf(build(f)),
+			reflect.Zero(errorType),
+		}
+	})
+
+	return constructor.Interface(), nil
+}
+
+// isNil returns if v is a nil value of a type that can be nil.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// convert converts fn to F if it implements F or is a function with the
+// same signature.
+func convert[F any](fn any) (F, bool) {
+	if f, ok := fn.(F); ok {
+		return f, true
+	}
+
+	var zero F
+	fnType := reflect.TypeOf(fn)
+	funcType := reflect.TypeFor[F]()
+	if fnType != nil && funcType.Kind() == reflect.Func && fnType.ConvertibleTo(funcType) {
+		return reflect.ValueOf(fn).Convert(funcType).Interface().(F), true
+	}
+
+	return zero, false
+}
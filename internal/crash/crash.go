@@ -0,0 +1,176 @@
+package crash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/shutdown"
+	"github.com/caarlos0/env/v11"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ExitCode is the exit code used when the process exits due to a panic. The
+// Go runtime exits with 2 on unrecovered panics, which is also used for
+// usage errors, so EX_SOFTWARE from sysexits.h is used instead.
+const ExitCode = 70
+
+// Config controls how crashes are reported.
+type Config struct {
+	// ReportDirectory is a directory where a crash report is written when
+	// the process panics. No report is written if empty.
+	ReportDirectory string `env:"REPORT_DIRECTORY"`
+}
+
+// Reporter reports panics through the root logger and the active span,
+// flushes telemetry and exits the process.
+type Reporter struct {
+	logger      *zap.Logger
+	config      Config
+	service     internal.ServiceInfo
+	coordinator *shutdown.Coordinator
+
+	// Exit is called to exit the process after a panic has been reported,
+	// defaults to os.Exit.
+	Exit func(code int)
+
+	mutex sync.Mutex
+}
+
+var defaultReporter atomic.Pointer[Reporter]
+
+// NewReporter reads the crash configuration from the environment and creates
+// a reporter.
+func NewReporter(
+	logger *zap.Logger,
+	service internal.ServiceInfo,
+	coordinator *shutdown.Coordinator,
+) (*Reporter, error) {
+	config, err := env.ParseAsWithOptions[Config](env.Options{
+		Prefix: "CRASH_",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reporter{
+		// The stack of the panic is logged, so skip the stack of the reporter
+		logger:      logger.WithOptions(zap.AddStacktrace(zap.FatalLevel)),
+		config:      config,
+		service:     service,
+		coordinator: coordinator,
+		Exit:        os.Exit,
+	}, nil
+}
+
+// SetDefault sets the reporter used by Report.
+func SetDefault(reporter *Reporter) {
+	defaultReporter.Store(reporter)
+}
+
+// Report reports a panic using the default reporter. If no reporter has been
+// set the panic is written to stderr before exiting.
+func Report(ctx context.Context, name string, value any, stack []byte) {
+	reporter := defaultReporter.Load()
+	if reporter == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "panic in %s: %v\n\n%s", name, value, stack)
+		os.Exit(ExitCode)
+	}
+
+	reporter.Report(ctx, name, value, stack)
+}
+
+// Recover reports a panic using the default reporter. It must be deferred
+// directly, such as in lifecycle hooks that run code provided by the
+// application, as Fx runs hooks in a goroutine of its own.
+func Recover(ctx context.Context, name string) {
+	if value := recover(); value != nil {
+		Report(ctx, name, value, debug.Stack())
+	}
+}
+
+// Report logs the panic with its stack, marks the span in the context as
+// failed, writes a crash report if configured, flushes telemetry and exits
+// the process. Report does not return.
+func (r *Reporter) Report(ctx context.Context, name string, value any, stack []byte) {
+	// Only the first panic is reported, other goroutines that panic while
+	// the report is in progress block until the process exits
+	r.mutex.Lock()
+
+	err := asError(value)
+	r.logger.Error(
+		"Panic recovered, exiting",
+		zap.String("goroutine", name),
+		zap.Error(err),
+		zap.String("stack", string(stack)),
+	)
+
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "panic: "+err.Error())
+		// The owner of the span will never end it, so end it here to have
+		// it exported
+		span.End()
+	}
+
+	if r.config.ReportDirectory != "" {
+		path, err := r.writeReport(name, value, stack)
+		if err != nil {
+			r.logger.Error("Unable to write crash report", zap.Error(err))
+		} else {
+			r.logger.Info("Wrote crash report", zap.String("path", path))
+		}
+	}
+
+	_ = r.coordinator.Flush()
+	_ = r.logger.Sync()
+	r.Exit(ExitCode)
+}
+
+// writeReport writes a crash report to the report directory.
+func (r *Reporter) writeReport(name string, value any, stack []byte) (string, error) {
+	err := os.MkdirAll(r.config.ReportDirectory, 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	path := filepath.Join(
+		r.config.ReportDirectory,
+		"crash-"+now.Format("20060102T150405Z")+"-"+strconv.Itoa(os.Getpid())+".txt",
+	)
+
+	report := fmt.Sprintf(
+		"service: %s\nversion: %s\ntime: %s\ngoroutine: %s\npanic: %v\n\n%s",
+		r.service.Name,
+		r.service.Version,
+		now.Format(time.RFC3339Nano),
+		name,
+		value,
+		stack,
+	)
+
+	err = os.WriteFile(path, []byte(report), 0o644) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func asError(value any) error {
+	if err, ok := value.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(value))
+}
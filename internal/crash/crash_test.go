@@ -0,0 +1,132 @@
+package crash_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"github.com/aholstenson/sprout-go/internal/shutdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("Reporter", func() {
+	var logs *observer.ObservedLogs
+	var reporter *crash.Reporter
+	var coordinator *shutdown.Coordinator
+	var exitCode int
+
+	newReporter := func() {
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+
+		var err error
+		coordinator, err = shutdown.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		reporter, err = crash.NewReporter(zap.New(core), internal.ServiceInfo{
+			Name:    "test",
+			Version: "v1.0.0",
+		}, coordinator)
+		Expect(err).ToNot(HaveOccurred())
+
+		exitCode = -1
+		reporter.Exit = func(code int) {
+			exitCode = code
+		}
+	}
+
+	It("logs the panic with its stack and exits", func() {
+		newReporter()
+
+		reporter.Report(context.Background(), "worker", "boom", []byte("goroutine 1 [running]"))
+
+		Expect(exitCode).To(Equal(crash.ExitCode))
+		entries := logs.FilterMessage("Panic recovered, exiting").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("goroutine", "worker"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("error", "boom"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("stack", "goroutine 1 [running]"))
+	})
+
+	It("flushes before exiting", func() {
+		newReporter()
+
+		flushed := false
+		coordinator.Register("test", func(ctx context.Context) error {
+			Expect(exitCode).To(Equal(-1))
+			flushed = true
+			return nil
+		})
+
+		reporter.Report(context.Background(), "worker", "boom", nil)
+
+		Expect(flushed).To(BeTrue())
+		Expect(exitCode).To(Equal(crash.ExitCode))
+	})
+
+	It("marks the active span as failed", func() {
+		newReporter()
+
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		ctx, _ := provider.Tracer("test").Start(context.Background(), "operation")
+
+		reporter.Report(ctx, "worker", "boom", nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Events()).ToNot(BeEmpty())
+	})
+
+	It("writes a crash report if configured", func() {
+		dir := GinkgoT().TempDir()
+		GinkgoT().Setenv("CRASH_REPORT_DIRECTORY", dir)
+		newReporter()
+
+		reporter.Report(context.Background(), "worker", "boom", []byte("goroutine 1 [running]"))
+
+		files, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+
+		data, err := os.ReadFile(files[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("service: test"))
+		Expect(string(data)).To(ContainSubstring("panic: boom"))
+		Expect(string(data)).To(ContainSubstring("goroutine 1 [running]"))
+	})
+
+	It("reports panics recovered in lifecycle hooks", func() {
+		newReporter()
+		crash.SetDefault(reporter)
+		DeferCleanup(crash.SetDefault, (*crash.Reporter)(nil))
+
+		app := fx.New(
+			fx.NopLogger,
+			fx.Invoke(func(lifecycle fx.Lifecycle) {
+				lifecycle.Append(fx.StartHook(func(ctx context.Context) error {
+					defer crash.Recover(ctx, "OnStart hook")
+					panic("boom")
+				}))
+			}),
+		)
+
+		Expect(app.Start(context.Background())).To(Succeed())
+		Expect(exitCode).To(Equal(crash.ExitCode))
+		entries := logs.FilterMessage("Panic recovered, exiting").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("goroutine", "OnStart hook"))
+		Expect(app.Stop(context.Background())).To(Succeed())
+	})
+})
@@ -0,0 +1,136 @@
+package diagnostics_test
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/aholstenson/sprout-go/internal/diagnostics"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/startup"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Diagnostics", func() {
+	AfterEach(func() {
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	get := func(path string) (int, string) {
+		res, err := http.Get("http://localhost:8088" + path)
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return res.StatusCode, string(body)
+	}
+
+	It("endpoints are not available by default", func() {
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			diagnostics.Module,
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, _ := get("/debug/pprof/")
+		Expect(status).To(Equal(http.StatusNotFound))
+	})
+
+	It("endpoints are available when enabled", func() {
+		t := GinkgoT()
+		t.Setenv("DIAGNOSTICS_ENABLED", "true")
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			diagnostics.Module,
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, body := get("/debug/pprof/")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("goroutine"))
+
+		status, _ = get("/debug/pprof/heap")
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body = get("/debug/goroutines")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("goroutine"))
+
+		status, body = get("/debug/gc")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("numGC"))
+
+		status, body = get("/debug/runtime")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("gomaxprocs"))
+
+		status, _ = get("/debug/buildinfo")
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body = get("/debug/graph")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("digraph"))
+
+		status, _ = get("/debug/startup")
+		Expect(status).To(Equal(http.StatusNotFound))
+
+		status, body = get("/debug/modules")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON("[]"))
+	})
+
+	It("startup report is available when recorded", func() {
+		t := GinkgoT()
+		t.Setenv("DIAGNOSTICS_ENABLED", "true")
+
+		recorder, err := startup.NewRecorder(zaptest.NewLogger(t), fxevent.NopLogger)
+		Expect(err).ToNot(HaveOccurred())
+
+		app := fxtest.New(
+			t,
+			fx.WithLogger(func() fxevent.Logger { return recorder }),
+			fx.Supply(recorder),
+			logging.Module(zaptest.NewLogger(t)),
+			health.Module,
+			diagnostics.Module,
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, body := get("/debug/startup")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring(`"steps"`))
+		Expect(body).To(ContainSubstring(`"kind": "start"`))
+	})
+
+	It("endpoints require authentication if configured", func() {
+		t := GinkgoT()
+		t.Setenv("DIAGNOSTICS_ENABLED", "true")
+		t.Setenv("HEALTH_SERVER_AUTH_TOKEN", "secret")
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			diagnostics.Module,
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, _ := get("/debug/pprof/")
+		Expect(status).To(Equal(http.StatusUnauthorized))
+	})
+})
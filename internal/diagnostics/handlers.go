@@ -0,0 +1,185 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	goruntime "runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/module"
+	"github.com/aholstenson/sprout-go/internal/runtime"
+	"github.com/aholstenson/sprout-go/internal/startup"
+	"go.uber.org/fx"
+)
+
+func registerRoutes(
+	routes health.Routes,
+	tuner *runtime.Tuner,
+	graph fx.DotGraph,
+	recorder *startup.Recorder,
+	modules []module.Info,
+) {
+	routes.AddRoute("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	routes.AddRoute("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	routes.AddRoute("/debug/pprof/profile", withoutWriteDeadline(http.HandlerFunc(pprof.Profile)))
+	routes.AddRoute("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	routes.AddRoute("/debug/pprof/trace", withoutWriteDeadline(http.HandlerFunc(pprof.Trace)))
+
+	routes.AddRoute("GET /debug/goroutines", http.HandlerFunc(goroutines))
+	routes.AddRoute("GET /debug/buildinfo", http.HandlerFunc(buildInfo))
+	routes.AddRoute("GET /debug/gc", http.HandlerFunc(gcStats))
+	routes.AddRoute("GET /debug/runtime", runtimeValues(tuner))
+	routes.AddRoute("GET /debug/graph", dotGraph(graph))
+	routes.AddRoute("GET /debug/modules", moduleList(modules))
+	if recorder != nil {
+		routes.AddRoute("GET /debug/startup", startupReport(recorder))
+	}
+}
+
+// withoutWriteDeadline removes the write deadline of the server for handlers
+// that stream data for longer than the server allows, such as CPU profiles.
+func withoutWriteDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		next.ServeHTTP(w, r)
+	})
+}
+
+// goroutines writes a dump of the stacks of all goroutines.
+func goroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+func buildInfo(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "build info not available", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(info.String()))
+}
+
+type gcResponse struct {
+	NumGC          int64           `json:"numGC"`
+	LastGC         time.Time       `json:"lastGC"`
+	PauseTotal     time.Duration   `json:"pauseTotalNs"`
+	RecentPauses   []time.Duration `json:"recentPausesNs"`
+	HeapAlloc      uint64          `json:"heapAllocBytes"`
+	HeapSys        uint64          `json:"heapSysBytes"`
+	HeapObjects    uint64          `json:"heapObjects"`
+	NextGC         uint64          `json:"nextGCBytes"`
+	TotalAlloc     uint64          `json:"totalAllocBytes"`
+	Sys            uint64          `json:"sysBytes"`
+	GCCPUFraction  float64         `json:"gcCPUFraction"`
+	NumGoroutine   int             `json:"numGoroutine"`
+	NumForcedGC    uint32          `json:"numForcedGC"`
+	StackInUse     uint64          `json:"stackInUseBytes"`
+	PauseQuantiles []time.Duration `json:"pauseQuantilesNs,omitempty"`
+}
+
+func gcStats(w http.ResponseWriter, r *http.Request) {
+	stats := debug.GCStats{
+		// Request min, 25%, 50%, 75% and max pause times
+		PauseQuantiles: make([]time.Duration, 5),
+	}
+	debug.ReadGCStats(&stats)
+
+	var memStats goruntime.MemStats
+	goruntime.ReadMemStats(&memStats)
+
+	recentPauses := stats.Pause
+	if len(recentPauses) > 10 {
+		recentPauses = recentPauses[:10]
+	}
+
+	response := gcResponse{
+		NumGC:          stats.NumGC,
+		LastGC:         stats.LastGC,
+		PauseTotal:     stats.PauseTotal,
+		RecentPauses:   recentPauses,
+		HeapAlloc:      memStats.HeapAlloc,
+		HeapSys:        memStats.HeapSys,
+		HeapObjects:    memStats.HeapObjects,
+		NextGC:         memStats.NextGC,
+		TotalAlloc:     memStats.TotalAlloc,
+		Sys:            memStats.Sys,
+		GCCPUFraction:  memStats.GCCPUFraction,
+		NumGoroutine:   goruntime.NumGoroutine(),
+		NumForcedGC:    memStats.NumForcedGC,
+		StackInUse:     memStats.StackInuse,
+		PauseQuantiles: stats.PauseQuantiles,
+	}
+
+	writeJSON(w, response)
+}
+
+type runtimeResponse struct {
+	GOMAXPROCS      int    `json:"gomaxprocs"`
+	GOMEMLIMIT      int64  `json:"gomemlimit,omitempty"`
+	GOGC            int64  `json:"gogc"`
+	AvailableMemory int64  `json:"availableMemory,omitempty"`
+	NumCPU          int    `json:"numCPU"`
+	GoVersion       string `json:"goVersion"`
+}
+
+// runtimeValues reports the runtime settings in effect, including the ones
+// set by Sprout when the application started.
+func runtimeValues(tuner *runtime.Tuner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := runtime.CurrentValues()
+		if tuner != nil {
+			values = tuner.Values()
+		}
+
+		writeJSON(w, runtimeResponse{
+			GOMAXPROCS:      values.MaxProcs,
+			GOMEMLIMIT:      values.MemoryLimit,
+			GOGC:            values.GOGC,
+			AvailableMemory: values.AvailableMemory,
+			NumCPU:          goruntime.NumCPU(),
+			GoVersion:       goruntime.Version(),
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// dotGraph writes the dependency graph of the application in the DOT format
+// of Graphviz.
+func dotGraph(graph fx.DotGraph) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		_, _ = w.Write([]byte(graph))
+	}
+}
+
+// startupReport reports how long each step of starting the application took.
+func startupReport(recorder *startup.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, recorder.Report())
+	}
+}
+
+// moduleList reports the modules of the application and if they are enabled.
+func moduleList(modules []module.Info) http.HandlerFunc {
+	sorted := module.Sorted(modules)
+	if sorted == nil {
+		sorted = []module.Info{}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, sorted)
+	}
+}
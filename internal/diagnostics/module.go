@@ -0,0 +1,46 @@
+package diagnostics
+
+import (
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/module"
+	"github.com/aholstenson/sprout-go/internal/runtime"
+	"github.com/aholstenson/sprout-go/internal/startup"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Enabled controls if diagnostic endpoints are added to the health server
+	Enabled bool `env:"ENABLED" envDefault:"false"`
+}
+
+var Module = fx.Module(
+	"sprout:diagnostics",
+	fx.Provide(config.Config("DIAGNOSTICS", Config{}), fx.Private),
+	fx.Provide(logging.Logger("diagnostics"), fx.Private),
+	fx.Invoke(register),
+)
+
+type params struct {
+	fx.In
+
+	Config Config
+	Logger *zap.Logger
+	Routes health.Routes
+	Tuner  *runtime.Tuner `optional:"true"`
+	Graph  fx.DotGraph
+	// Startup is available when running via Sprout, but not in tests
+	Startup *startup.Recorder `optional:"true"`
+	Modules []module.Info     `group:"sprout:modules"`
+}
+
+func register(p params) {
+	if !p.Config.Enabled {
+		return
+	}
+
+	p.Logger.Info("Diagnostic endpoints enabled", zap.String("path", "/debug/"))
+	registerRoutes(p.Routes, p.Tuner, p.Graph, p.Startup, p.Modules)
+}
@@ -0,0 +1,71 @@
+package flags
+
+import (
+	"context"
+	"maps"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// Domain is the OpenFeature domain the provider of Sprout is registered
+// for. Clients created via openfeature.NewClient(Domain) use the same flags
+// as Flags.
+const Domain = "sprout"
+
+// Attributes describe the subject a flag is evaluated for, such as the
+// country of a user. The key openfeature.TargetingKey identifies the
+// subject.
+type Attributes map[string]any
+
+// Flags evaluates feature flags. If a flag can not be evaluated the default
+// value is returned.
+type Flags struct {
+	client *openfeature.Client
+}
+
+func newFlags(client *openfeature.Client) *Flags {
+	return &Flags{
+		client: client,
+	}
+}
+
+// Bool evaluates a boolean flag.
+func (f *Flags) Bool(ctx context.Context, flag string, defaultValue bool, attributes ...Attributes) bool {
+	value, _ := f.client.BooleanValue(ctx, flag, defaultValue, evaluationContext(attributes))
+	return value
+}
+
+// String evaluates a string flag.
+func (f *Flags) String(ctx context.Context, flag string, defaultValue string, attributes ...Attributes) string {
+	value, _ := f.client.StringValue(ctx, flag, defaultValue, evaluationContext(attributes))
+	return value
+}
+
+// Int evaluates an integer flag.
+func (f *Flags) Int(ctx context.Context, flag string, defaultValue int64, attributes ...Attributes) int64 {
+	value, _ := f.client.IntValue(ctx, flag, defaultValue, evaluationContext(attributes))
+	return value
+}
+
+// Float evaluates a number flag.
+func (f *Flags) Float(ctx context.Context, flag string, defaultValue float64, attributes ...Attributes) float64 {
+	value, _ := f.client.FloatValue(ctx, flag, defaultValue, evaluationContext(attributes))
+	return value
+}
+
+// Client returns the OpenFeature client used to evaluate flags, which can
+// be used to get the details of an evaluation.
+func (f *Flags) Client() *openfeature.Client {
+	return f.client
+}
+
+func evaluationContext(attributes []Attributes) openfeature.EvaluationContext {
+	merged := map[string]any{}
+	for _, a := range attributes {
+		maps.Copy(merged, a)
+	}
+
+	targetingKey, _ := merged[openfeature.TargetingKey].(string)
+	delete(merged, openfeature.TargetingKey)
+	return openfeature.NewEvaluationContext(targetingKey, merged)
+}
@@ -0,0 +1,317 @@
+package flags_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/flags"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/open-feature/go-sdk/openfeature/memprovider"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+const definitions = `{
+	"new-checkout": {
+		"value": false,
+		"rules": [
+			{"match": {"country": ["SE", "NO"]}, "value": true},
+			{"match": {"targetingKey": ["beta-user"]}, "value": true}
+		]
+	},
+	"theme": {"value": "dark"},
+	"batch-size": {"value": 100},
+	"ratio": {"value": 0.25},
+	"old-checkout": {"value": true, "disabled": true}
+}`
+
+var _ = Describe("Flags", func() {
+	var reader *sdkmetric.ManualReader
+
+	writeFile := func(content string) string {
+		file := filepath.Join(GinkgoT().TempDir(), "flags.json")
+		Expect(os.WriteFile(file, []byte(content), 0o600)).To(Succeed())
+		GinkgoT().Setenv("FLAGS_FILE", file)
+		return file
+	}
+
+	newFlags := func(options ...fx.Option) *flags.Flags {
+		reader = sdkmetric.NewManualReader()
+		meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		var f *flags.Flags
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(meterProvider, fx.As(new(metric.MeterProvider)))),
+			flags.Module,
+			fx.Options(options...),
+			fx.Populate(&f),
+		)
+		app.RequireStart()
+		DeferCleanup(app.RequireStop)
+		return f
+	}
+
+	ctx := context.Background()
+
+	It("returns the default value for unknown flags", func() {
+		f := newFlags()
+		Expect(f.Bool(ctx, "unknown", true)).To(BeTrue())
+		Expect(f.String(ctx, "unknown", "light")).To(Equal("light"))
+	})
+
+	It("reads flags from the environment", func() {
+		GinkgoT().Setenv("FLAG_NEW_CHECKOUT", "true")
+		GinkgoT().Setenv("FLAG_BATCH_SIZE", "50")
+		GinkgoT().Setenv("FLAG_RATIO", "0.5")
+		GinkgoT().Setenv("FLAG_THEME", "light")
+
+		f := newFlags()
+		Expect(f.Bool(ctx, "new-checkout", false)).To(BeTrue())
+		Expect(f.Int(ctx, "batch-size", 10)).To(Equal(int64(50)))
+		Expect(f.Float(ctx, "ratio", 1)).To(Equal(0.5))
+		Expect(f.String(ctx, "theme", "dark")).To(Equal("light"))
+	})
+
+	It("applies overrides to the environment", func() {
+		GinkgoT().Setenv("FLAG_THEME", "light")
+
+		f := newFlags(fx.Supply(config.Overrides{
+			"FLAG_THEME":        "blue",
+			"FLAG_NEW_CHECKOUT": "true",
+		}))
+		Expect(f.String(ctx, "theme", "dark")).To(Equal("blue"))
+		Expect(f.Bool(ctx, "new-checkout", false)).To(BeTrue())
+	})
+
+	It("reads flags from a file", func() {
+		writeFile(definitions)
+
+		f := newFlags()
+		Expect(f.Bool(ctx, "new-checkout", true)).To(BeFalse())
+		Expect(f.String(ctx, "theme", "light")).To(Equal("dark"))
+		Expect(f.Int(ctx, "batch-size", 10)).To(Equal(int64(100)))
+		Expect(f.Float(ctx, "ratio", 1)).To(Equal(0.25))
+		Expect(f.Bool(ctx, "old-checkout", false)).To(BeFalse())
+	})
+
+	It("prefers the environment over the file", func() {
+		writeFile(definitions)
+		GinkgoT().Setenv("FLAG_THEME", "light")
+
+		f := newFlags()
+		Expect(f.String(ctx, "theme", "dark")).To(Equal("light"))
+	})
+
+	It("evaluates rules against attributes", func() {
+		writeFile(definitions)
+
+		f := newFlags()
+		Expect(f.Bool(ctx, "new-checkout", false, flags.Attributes{"country": "SE"})).To(BeTrue())
+		Expect(f.Bool(ctx, "new-checkout", false, flags.Attributes{"country": "DK"})).To(BeFalse())
+		Expect(f.Bool(ctx, "new-checkout", false, flags.Attributes{openfeature.TargetingKey: "beta-user"})).To(BeTrue())
+
+		details, err := f.Client().BooleanValueDetails(ctx, "new-checkout", false, openfeature.NewEvaluationContext("", map[string]any{"country": "NO"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(details.Reason).To(Equal(openfeature.TargetingMatchReason))
+		Expect(details.Variant).To(Equal("rule-1"))
+	})
+
+	It("returns the default value if the type does not match", func() {
+		writeFile(definitions)
+		GinkgoT().Setenv("FLAG_BATCH_SIZE", "many")
+
+		f := newFlags()
+		Expect(f.Bool(ctx, "theme", true)).To(BeTrue())
+		Expect(f.Int(ctx, "ratio", 10)).To(Equal(int64(10)))
+		Expect(f.Int(ctx, "batch-size", 10)).To(Equal(int64(10)))
+
+		details, err := f.Client().IntValueDetails(ctx, "batch-size", 10, openfeature.EvaluationContext{})
+		Expect(err).To(HaveOccurred())
+		Expect(details.ErrorCode).To(Equal(openfeature.ParseErrorCode))
+	})
+
+	It("fails if the file is invalid", func() {
+		writeFile(`{"theme": {"value": "dark", "unknown": true}}`)
+
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(sdkmetric.NewMeterProvider(), fx.As(new(metric.MeterProvider)))),
+			flags.Module,
+			fx.Invoke(func(*flags.Flags) {}),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("invalid feature flag file")))
+	})
+
+	It("reports an invalid file when validating", func() {
+		writeFile(`{"theme": {"value": "dark", "unknown": true}}`)
+		validation := &config.Validation{}
+
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(sdkmetric.NewMeterProvider(), fx.As(new(metric.MeterProvider)))),
+			fx.Supply(validation),
+			flags.Module,
+			fx.Invoke(func(*flags.Flags) {}),
+		)
+		Expect(app.Err()).ToNot(HaveOccurred())
+		Expect(validation.Errors()).To(ContainElement(MatchError(ContainSubstring("invalid feature flag file"))))
+	})
+
+	It("reloads the file when it changes", func() {
+		file := writeFile(definitions)
+		GinkgoT().Setenv("FLAGS_RELOAD_INTERVAL", "20ms")
+
+		f := newFlags()
+		Expect(f.String(ctx, "theme", "light")).To(Equal("dark"))
+
+		// A broken file keeps the previous flags
+		Expect(os.WriteFile(file, []byte(`{`), 0o600)).To(Succeed())
+		later := time.Now().Add(time.Second)
+		Expect(os.Chtimes(file, later, later)).To(Succeed())
+		Consistently(func() string {
+			return f.String(ctx, "theme", "light")
+		}, "100ms", "10ms").Should(Equal("dark"))
+
+		Expect(os.WriteFile(file, []byte(`{"theme": {"value": "blue"}}`), 0o600)).To(Succeed())
+		later = later.Add(time.Second)
+		Expect(os.Chtimes(file, later, later)).To(Succeed())
+		Eventually(func() string {
+			return f.String(ctx, "theme", "light")
+		}).Should(Equal("blue"))
+	})
+
+	It("records evaluations as span events and metrics", func() {
+		writeFile(definitions)
+
+		f := newFlags()
+
+		spans := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+		spanCtx, span := tracerProvider.Tracer("test").Start(ctx, "test")
+		f.Bool(spanCtx, "new-checkout", false, flags.Attributes{"country": "SE"})
+		f.Bool(spanCtx, "unknown", false)
+		span.End()
+
+		events := spans.Ended()[0].Events()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Name).To(Equal("feature_flag.evaluation"))
+		Expect(events[0].Attributes).To(ContainElements(
+			attribute.String("feature_flag.key", "new-checkout"),
+			attribute.String("feature_flag.provider.name", "sprout"),
+			attribute.String("feature_flag.result.reason", "targeting_match"),
+			attribute.String("feature_flag.result.variant", "rule-1"),
+		))
+		Expect(events[1].Attributes).To(ContainElement(
+			attribute.String("error.type", "flag_not_found"),
+		))
+
+		var data metricdata.ResourceMetrics
+		Expect(reader.Collect(ctx, &data)).To(Succeed())
+		Expect(data.ScopeMetrics).To(HaveLen(1))
+		sum, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+		Expect(ok).To(BeTrue())
+		Expect(sum.DataPoints).To(HaveLen(2))
+	})
+
+	It("can use another provider", func() {
+		f := newFlags(fx.Provide(fx.Annotate(
+			func() openfeature.FeatureProvider {
+				return memprovider.NewInMemoryProvider(map[string]memprovider.InMemoryFlag{
+					"theme": {
+						State:          memprovider.Enabled,
+						DefaultVariant: "on",
+						Variants:       map[string]any{"on": "green"},
+					},
+				})
+			},
+			fx.ResultTags(`name:"sprout:flags:provider"`),
+		)))
+
+		Expect(f.String(ctx, "theme", "light")).To(Equal("green"))
+	})
+
+	It("only registers the provider while the application is running", func() {
+		start := func(theme string) (*fxtest.App, *flags.Flags) {
+			GinkgoT().Setenv("FLAG_THEME", theme)
+
+			var f *flags.Flags
+			app := fxtest.New(
+				GinkgoT(),
+				logging.Module(zaptest.NewLogger(GinkgoT())),
+				fx.Supply(fx.Annotate(sdkmetric.NewMeterProvider(), fx.As(new(metric.MeterProvider)))),
+				flags.Module,
+				fx.Populate(&f),
+			)
+			app.RequireStart()
+			return app, f
+		}
+
+		first, firstFlags := start("light")
+		Expect(firstFlags.String(ctx, "theme", "default")).To(Equal("light"))
+
+		first.RequireStop()
+		Expect(firstFlags.String(ctx, "theme", "default")).To(Equal("default"))
+
+		second, secondFlags := start("dark")
+		defer second.RequireStop()
+		Expect(secondFlags.String(ctx, "theme", "default")).To(Equal("dark"))
+	})
+
+	It("shuts down the provider when stopping", func() {
+		provider := &stateProvider{}
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(sdkmetric.NewMeterProvider(), fx.As(new(metric.MeterProvider)))),
+			flags.Module,
+			fx.Provide(fx.Annotate(
+				func() openfeature.FeatureProvider { return provider },
+				fx.ResultTags(`name:"sprout:flags:provider"`),
+			)),
+			fx.Invoke(func(*flags.Flags) {}),
+		)
+		Expect(provider.initialized.Load()).To(BeFalse())
+
+		app.RequireStart()
+		Expect(provider.initialized.Load()).To(BeTrue())
+		Expect(provider.shutdown.Load()).To(BeFalse())
+
+		app.RequireStop()
+		Eventually(provider.shutdown.Load).Should(BeTrue())
+	})
+})
+
+// stateProvider records when it is initialized and shut down.
+type stateProvider struct {
+	openfeature.NoopProvider
+
+	initialized atomic.Bool
+	shutdown    atomic.Bool
+}
+
+func (p *stateProvider) Init(evaluationContext openfeature.EvaluationContext) error {
+	p.initialized.Store(true)
+	return nil
+}
+
+func (p *stateProvider) Shutdown() {
+	p.shutdown.Store(true)
+}
@@ -0,0 +1,101 @@
+package flags
+
+import (
+	"context"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// File is a JSON file defining flags
+	File string `env:"FILE"`
+	// ReloadInterval is how often File is checked for changes, reloading
+	// is disabled if zero
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL" envDefault:"10s"`
+}
+
+// Module provides *Flags, evaluating flags via the provider named
+// sprout:flags:provider if available and otherwise via a Provider
+// configured from the environment.
+var Module = fx.Module(
+	"sprout:flags",
+	fx.Provide(config.Config("FLAGS", Config{}), fx.Private),
+	fx.Provide(logging.Logger("flags"), fx.Private),
+	fx.Provide(provide),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle     fx.Lifecycle
+	Logger        *zap.Logger
+	Config        Config
+	MeterProvider metric.MeterProvider
+	Validation    *config.Validation `optional:"true"`
+	Overrides     config.Overrides   `optional:"true"`
+
+	Provider openfeature.FeatureProvider `name:"sprout:flags:provider" optional:"true"`
+}
+
+func provide(p params) (*Flags, error) {
+	provider := p.Provider
+	if provider == nil {
+		environment := config.Environment(p.Overrides)
+		configProvider, err := NewProvider(p.Logger, p.Config.File, environment)
+		if err != nil {
+			if p.Validation == nil {
+				return nil, err
+			}
+
+			// Keep resolving so every configuration error is reported
+			p.Validation.Add(err)
+			configProvider, err = NewProvider(p.Logger, "", environment)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if p.Config.File != "" {
+			p.Logger.Info("Loaded feature flags", zap.String("file", p.Config.File), zap.Strings("flags", configProvider.Flags()))
+		}
+
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				configProvider.Start(p.Config.ReloadInterval)
+				return nil
+			},
+			OnStop: configProvider.Stop,
+		})
+		provider = configProvider
+	}
+
+	hook, err := newTelemetryHook(p.MeterProvider.Meter("sprout/flags"))
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider is registered globally, so only while the application
+	// is running. Replacing it on stop shuts it down if it is an
+	// openfeature.StateHandler. Registering initializes the provider, so
+	// panics in it are reported.
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			defer crash.Recover(ctx, "flags OnStart hook")
+			return openfeature.SetNamedProviderAndWait(Domain, provider)
+		},
+		OnStop: func(ctx context.Context) error {
+			return openfeature.SetNamedProviderAndWait(Domain, openfeature.NoopProvider{})
+		},
+	})
+
+	client := openfeature.NewClient(Domain)
+	client.AddHooks(hook)
+	return newFlags(client), nil
+}
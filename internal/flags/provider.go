@@ -0,0 +1,423 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.uber.org/zap"
+)
+
+// ProviderName is the name of the provider backed by the environment and a
+// file.
+const ProviderName = "sprout"
+
+// Definition is a flag defined in a file.
+type Definition struct {
+	// Value is returned when no rule matches
+	Value any `json:"value"`
+	// Disabled flags return the default value of the caller
+	Disabled bool `json:"disabled,omitempty"`
+	// Rules are checked in order and the value of the first match is used
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule returns a value when all of its attributes match.
+type Rule struct {
+	// Match contains attributes of the evaluation context and the values
+	// they can have for the rule to match
+	Match map[string][]any `json:"match"`
+	Value any              `json:"value"`
+}
+
+func (r Rule) matches(flatCtx openfeature.FlattenedContext) bool {
+	for attribute, values := range r.Match {
+		actual, ok := flatCtx[attribute]
+		if !ok {
+			return false
+		}
+
+		if !slices.ContainsFunc(values, func(v any) bool {
+			return fmt.Sprint(v) == fmt.Sprint(actual)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Provider is an OpenFeature provider that reads flags from environment
+// variables named FLAG_<KEY> and from a JSON file that is reloaded when it
+// changes. Environment variables take precedence over the file.
+//
+// The keys of flags are not known ahead of time, so the environment
+// variables can not be bound to a struct via Config. Instead every variable
+// with the FLAG_ prefix is read from the environment passed when the
+// provider is created.
+type Provider struct {
+	logger      *zap.Logger
+	file        string
+	environment map[string]string
+
+	definitions atomic.Pointer[map[string]Definition]
+	modTime     time.Time
+	events      chan openfeature.Event
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+var (
+	_ openfeature.FeatureProvider = (*Provider)(nil)
+	_ openfeature.EventHandler    = (*Provider)(nil)
+)
+
+// NewProvider creates a provider with the flags of environment, loading the
+// definitions of file if it is not empty.
+func NewProvider(logger *zap.Logger, file string, environment map[string]string) (*Provider, error) {
+	p := &Provider{
+		logger:      logger,
+		file:        file,
+		environment: map[string]string{},
+		events:      make(chan openfeature.Event, 5),
+	}
+
+	for key, value := range environment {
+		if strings.HasPrefix(key, envPrefix) {
+			logger.Info("Read feature flag from environment", zap.String("key", key))
+			p.environment[key] = value
+		}
+	}
+
+	definitions := map[string]Definition{}
+	p.definitions.Store(&definitions)
+
+	if file != "" {
+		if _, err := p.reload(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Start reloads the file every interval until stopped.
+func (p *Provider) Start(interval time.Duration) {
+	if p.file == "" || interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	p.stop = stop
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.checkFile()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading the file.
+func (p *Provider) Stop(ctx context.Context) error {
+	if p.stop == nil {
+		return nil
+	}
+
+	close(p.stop)
+	p.wg.Wait()
+	return nil
+}
+
+func (p *Provider) checkFile() {
+	info, err := os.Stat(p.file)
+	if err != nil {
+		p.logger.Warn("Could not check feature flag file", zap.String("file", p.file), zap.Error(err))
+		return
+	}
+
+	if info.ModTime().Equal(p.modTime) {
+		return
+	}
+
+	changed, err := p.reload()
+	if err != nil {
+		// Keep the previous flags so a bad edit does not change behavior
+		p.logger.Error("Could not reload feature flags, keeping previous flags", zap.String("file", p.file), zap.Error(err))
+		return
+	}
+
+	p.logger.Info("Reloaded feature flags", zap.String("file", p.file), zap.Strings("changed", changed))
+	if len(changed) == 0 {
+		return
+	}
+
+	select {
+	case p.events <- openfeature.Event{
+		ProviderName: ProviderName,
+		EventType:    openfeature.ProviderConfigChange,
+		ProviderEventDetails: openfeature.ProviderEventDetails{
+			Message:     "Feature flags reloaded",
+			FlagChanges: changed,
+		},
+	}:
+	default:
+		// Nobody is consuming events
+	}
+}
+
+// reload reads the file and returns the keys of flags that changed.
+func (p *Provider) reload() ([]string, error) {
+	info, err := os.Stat(p.file)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p.file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var definitions map[string]Definition
+	decoder := json.NewDecoder(f)
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&definitions); err != nil {
+		return nil, fmt.Errorf("invalid feature flag file %s: %w", p.file, err)
+	}
+
+	previous := *p.definitions.Load()
+	var changed []string
+	for key, definition := range definitions {
+		if old, ok := previous[key]; !ok || !reflect.DeepEqual(old, definition) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := definitions[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	slices.Sort(changed)
+
+	p.definitions.Store(&definitions)
+	p.modTime = info.ModTime()
+	return changed, nil
+}
+
+// Flags returns the keys of the flags defined in the file.
+func (p *Provider) Flags() []string {
+	return slices.Sorted(maps.Keys(*p.definitions.Load()))
+}
+
+func (p *Provider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: ProviderName}
+}
+
+func (p *Provider) Hooks() []openfeature.Hook {
+	return nil
+}
+
+func (p *Provider) EventChannel() <-chan openfeature.Event {
+	return p.events
+}
+
+// resolve finds the value of a flag, nil if the default value should be
+// used.
+func (p *Provider) resolve(flag string, flatCtx openfeature.FlattenedContext) (any, openfeature.ProviderResolutionDetail) {
+	if value, ok := p.environment[envKey(flag)]; ok {
+		return value, openfeature.ProviderResolutionDetail{
+			Reason: openfeature.StaticReason,
+		}
+	}
+
+	definition, ok := (*p.definitions.Load())[flag]
+	if !ok {
+		return nil, openfeature.ProviderResolutionDetail{
+			Reason:          openfeature.DefaultReason,
+			ResolutionError: openfeature.NewFlagNotFoundResolutionError("flag " + flag + " is not defined"),
+		}
+	}
+
+	if definition.Disabled {
+		return nil, openfeature.ProviderResolutionDetail{
+			Reason: openfeature.DisabledReason,
+		}
+	}
+
+	for i, rule := range definition.Rules {
+		if rule.matches(flatCtx) {
+			return rule.Value, openfeature.ProviderResolutionDetail{
+				Reason:  openfeature.TargetingMatchReason,
+				Variant: "rule-" + strconv.Itoa(i+1),
+			}
+		}
+	}
+
+	return definition.Value, openfeature.ProviderResolutionDetail{
+		Reason: openfeature.StaticReason,
+	}
+}
+
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, flatCtx openfeature.FlattenedContext) openfeature.BoolResolutionDetail {
+	value, detail := p.resolve(flag, flatCtx)
+	result := openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	if value == nil {
+		return result
+	}
+
+	switch v := value.(type) {
+	case bool:
+		result.Value = v
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			result.ProviderResolutionDetail = parseError(flag, err)
+			return result
+		}
+		result.Value = parsed
+	default:
+		result.ProviderResolutionDetail = typeMismatch(flag, "bool", value)
+		return result
+	}
+	return result
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, flatCtx openfeature.FlattenedContext) openfeature.StringResolutionDetail {
+	value, detail := p.resolve(flag, flatCtx)
+	result := openfeature.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	if value == nil {
+		return result
+	}
+
+	v, ok := value.(string)
+	if !ok {
+		result.ProviderResolutionDetail = typeMismatch(flag, "string", value)
+		return result
+	}
+	result.Value = v
+	return result
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, flatCtx openfeature.FlattenedContext) openfeature.FloatResolutionDetail {
+	value, detail := p.resolve(flag, flatCtx)
+	result := openfeature.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	if value == nil {
+		return result
+	}
+
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		result.ProviderResolutionDetail = typeMismatch(flag, "number", value)
+		return result
+	}
+
+	parsed, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		result.ProviderResolutionDetail = parseError(flag, err)
+		return result
+	}
+	result.Value = parsed
+	return result
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, flatCtx openfeature.FlattenedContext) openfeature.IntResolutionDetail {
+	value, detail := p.resolve(flag, flatCtx)
+	result := openfeature.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	if value == nil {
+		return result
+	}
+
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		result.ProviderResolutionDetail = typeMismatch(flag, "integer", value)
+		return result
+	}
+
+	parsed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		result.ProviderResolutionDetail = parseError(flag, err)
+		return result
+	}
+	result.Value = parsed
+	return result
+}
+
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, flatCtx openfeature.FlattenedContext) openfeature.InterfaceResolutionDetail {
+	value, detail := p.resolve(flag, flatCtx)
+	result := openfeature.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	if value != nil {
+		result.Value = value
+	}
+	return result
+}
+
+func typeMismatch(flag string, expected string, value any) openfeature.ProviderResolutionDetail {
+	return openfeature.ProviderResolutionDetail{
+		Reason: openfeature.ErrorReason,
+		ResolutionError: openfeature.NewTypeMismatchResolutionError(
+			fmt.Sprintf("flag %s is not a %s, got %T", flag, expected, value),
+		),
+	}
+}
+
+func parseError(flag string, err error) openfeature.ProviderResolutionDetail {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+
+	return openfeature.ProviderResolutionDetail{
+		Reason: openfeature.ErrorReason,
+		ResolutionError: openfeature.NewParseErrorResolutionError(
+			fmt.Sprintf("flag %s could not be parsed: %s", flag, err),
+		),
+	}
+}
+
+// envPrefix is the prefix of environment variables defining flags.
+const envPrefix = "FLAG_"
+
+// envKey returns the environment variable of a flag, such as
+// FLAG_NEW_CHECKOUT for new-checkout.
+func envKey(flag string) string {
+	return envPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, flag)
+}
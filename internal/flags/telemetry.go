@@ -0,0 +1,72 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/open-feature/go-sdk/openfeature/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// telemetryHook records evaluations as events on the current span and
+// counts them in metrics.
+type telemetryHook struct {
+	openfeature.UnimplementedHook
+
+	evaluations metric.Int64Counter
+}
+
+func newTelemetryHook(meter metric.Meter) (*telemetryHook, error) {
+	evaluations, err := meter.Int64Counter(
+		"sprout.flags.evaluations",
+		metric.WithDescription("Number of feature flag evaluations"),
+		metric.WithUnit("{evaluation}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &telemetryHook{
+		evaluations: evaluations,
+	}, nil
+}
+
+func (h *telemetryHook) Finally(ctx context.Context, hookContext openfeature.HookContext, details openfeature.InterfaceEvaluationDetails, hints openfeature.HookHints) {
+	event := telemetry.CreateEvaluationEvent(hookContext, details)
+
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		attributes := make([]attribute.KeyValue, 0, len(event.Attributes))
+		for key, value := range event.Attributes {
+			attributes = append(attributes, toAttribute(key, value))
+		}
+		span.AddEvent(event.Name, trace.WithAttributes(attributes...))
+	}
+
+	// Only low cardinality attributes are used for metrics
+	attributes := []attribute.KeyValue{
+		attribute.String(telemetry.FlagKey, hookContext.FlagKey()),
+		attribute.String(telemetry.ResultReasonKey, fmt.Sprint(event.Attributes[telemetry.ResultReasonKey])),
+	}
+	if errorType, ok := event.Attributes[telemetry.ErrorTypeKey]; ok {
+		attributes = append(attributes, attribute.String(telemetry.ErrorTypeKey, fmt.Sprint(errorType)))
+	}
+	h.evaluations.Add(ctx, 1, metric.WithAttributes(attributes...))
+}
+
+func toAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case bool:
+		return attribute.Bool(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case string:
+		return attribute.String(key, v)
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
@@ -0,0 +1,148 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *zap.Logger `name:"logging.zap"`
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Validation     *config.Validation `optional:"true"`
+	Overrides      config.Overrides   `optional:"true"`
+}
+
+// Provide creates a constructor for use with fx.Provide that creates a
+// *grpc.ClientConn configured via environment variables prefixed with
+// GRPC_CLIENT_<NAME>_. The connection is closed when the application stops.
+func Provide(name string) any {
+	return func(p params) (*grpc.ClientConn, error) {
+		logger := logging.CreateLogger(p.Logger, []string{"grpc", "client", name})
+
+		cfg, err := config.Load(configPrefix(name), Config{}, config.In{
+			Logger:     logging.CreateLogger(p.Logger, []string{"config"}),
+			Validation: p.Validation,
+			Overrides:  p.Overrides,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := newClient(cfg, logger, p.TracerProvider, p.MeterProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Lifecycle.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return conn.Close()
+			},
+		})
+		return conn, nil
+	}
+}
+
+func newClient(
+	cfg Config,
+	logger *zap.Logger,
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) (*grpc.ClientConn, error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(
+			otelgrpc.WithTracerProvider(tracerProvider),
+			otelgrpc.WithMeterProvider(meterProvider),
+			otelgrpc.WithPropagators(otel.GetTextMapPropagator()),
+		)),
+		grpc.WithChainUnaryInterceptor(
+			unaryTimeout(cfg.Timeout),
+			unaryLogging(logger),
+		),
+		grpc.WithChainStreamInterceptor(streamLogging(logger)),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize)),
+	}
+
+	if cfg.LoadBalancingPolicy != "" {
+		serviceConfig, err := json.Marshal(map[string]any{
+			"loadBalancingConfig": []map[string]any{
+				{cfg.LoadBalancingPolicy: map[string]any{}},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.WithDefaultServiceConfig(string(serviceConfig)))
+	}
+
+	return grpc.NewClient(cfg.Target, options...)
+}
+
+// unaryTimeout applies a deadline to calls that do not already have one.
+func unaryTimeout(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// unaryLogging logs every unary call at debug level.
+func unaryLogging(logger *zap.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logger.Debug(
+			"Completed call",
+			zap.String("method", method),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return err
+	}
+}
+
+// streamLogging logs every streaming call at debug level when it is opened.
+func streamLogging(logger *zap.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		logger.Debug(
+			"Opened stream",
+			zap.String("method", method),
+			zap.String("code", status.Code(err).String()),
+		)
+		return stream, err
+	}
+}
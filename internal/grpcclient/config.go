@@ -0,0 +1,80 @@
+package grpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Config struct {
+	// Target is the address of the server, using the naming syntax of gRPC
+	// such as dns:///payments:9090
+	Target string `env:"TARGET,required"`
+
+	// Insecure disables TLS, for use with servers that accept plaintext
+	// connections such as within a service mesh
+	Insecure bool `env:"INSECURE" envDefault:"false"`
+	// TLSCAFile is a PEM file with the certificate authorities used to verify
+	// the server, the system roots are used if empty
+	TLSCAFile string `env:"TLS_CA_FILE"`
+	// TLSServerName overrides the name used to verify the certificate of the
+	// server
+	TLSServerName string `env:"TLS_SERVER_NAME"`
+
+	// Timeout is the deadline applied to calls that do not already have one,
+	// 0 means no deadline
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"0s"`
+	// MaxRecvMsgSize is the maximum size in bytes of a received message
+	MaxRecvMsgSize int `env:"MAX_RECV_MSG_SIZE" envDefault:"4194304"`
+	// LoadBalancingPolicy is the load balancing policy to use, such as
+	// round_robin, defaults to pick_first
+	LoadBalancingPolicy string `env:"LOAD_BALANCING_POLICY"`
+}
+
+// tlsConfig creates the TLS configuration used to connect to the server.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.Insecure {
+		if c.TLSCAFile != "" || c.TLSServerName != "" {
+			return nil, errors.New("TLS options can not be used with an insecure client")
+		}
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.TLSServerName,
+	}
+
+	if c.TLSCAFile != "" {
+		data, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", c.TLSCAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// configPrefix returns the environment variable prefix for a client, such as
+// GRPC_CLIENT_PAYMENT_API for payment-api.
+func configPrefix(name string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+
+	return "GRPC_CLIENT_" + normalized
+}
@@ -0,0 +1,77 @@
+package grpcclient_test
+
+import (
+	"github.com/aholstenson/sprout-go/internal/grpcclient"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+var _ = Describe("gRPC client", func() {
+	newApp := func(name string, options ...fx.Option) *fx.App {
+		return fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return sdktrace.NewTracerProvider() }),
+			fx.Provide(grpcclient.Provide(name)),
+			fx.Options(options...),
+		)
+	}
+
+	It("is configured from the environment", func() {
+		t := GinkgoT()
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_TARGET", "dns:///payments:9090")
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_INSECURE", "true")
+
+		var conn *grpc.ClientConn
+		app := newApp("payment-api", fx.Populate(&conn))
+		Expect(app.Err()).ToNot(HaveOccurred())
+		Expect(conn.Target()).To(Equal("dns:///payments:9090"))
+	})
+
+	It("requires a target", func() {
+		var conn *grpc.ClientConn
+		app := newApp("payment-api", fx.Populate(&conn))
+		Expect(app.Err()).To(MatchError(ContainSubstring("failed to load configuration")))
+	})
+
+	It("can not combine TLS options with an insecure client", func() {
+		t := GinkgoT()
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_TARGET", "localhost:9090")
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_INSECURE", "true")
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_TLS_SERVER_NAME", "payments")
+
+		var conn *grpc.ClientConn
+		app := newApp("payment-api", fx.Populate(&conn))
+		Expect(app.Err()).To(MatchError(ContainSubstring("insecure")))
+	})
+
+	It("closes the connection when stopped", func() {
+		t := GinkgoT()
+		t.Setenv("GRPC_CLIENT_PAYMENT_API_TARGET", "localhost:9090")
+
+		var conn *grpc.ClientConn
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return sdktrace.NewTracerProvider() }),
+			fx.Provide(grpcclient.Provide("payment-api")),
+			fx.Populate(&conn),
+		)
+		app.RequireStart()
+		app.RequireStop()
+
+		Expect(conn.GetState()).To(Equal(connectivity.Shutdown))
+	})
+})
@@ -0,0 +1,18 @@
+package grpcserver_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGRPCServer(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8095")
+	t.Setenv("GRPC_PORT", "19090")
+	t.Setenv("GRPC_HEALTH_INTERVAL", "100ms")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gRPC Server Suite")
+}
@@ -0,0 +1,292 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aholstenson/sprout-go/internal/grpcclient"
+	"github.com/aholstenson/sprout-go/internal/grpcserver"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type echoServer interface {
+	Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+}
+
+type echo struct {
+	prefix string
+}
+
+func (e *echo) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	if in.Value == "panic" {
+		panic("echo panicked")
+	}
+
+	return wrapperspb.String(e.prefix + in.Value), nil
+}
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(echoServer).Echo(ctx, req.(*wrapperspb.StringValue))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+}
+
+var _ = Describe("gRPC server", func() {
+	var recorder *tracetest.SpanRecorder
+	var logs *observer.ObservedLogs
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+
+		t := GinkgoT()
+		t.Setenv("GRPC_CLIENT_TEST_TARGET", "localhost:19090")
+		t.Setenv("GRPC_CLIENT_TEST_INSECURE", "true")
+	})
+
+	newApp := func(options ...fx.Option) *fxtest.App {
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+
+		return fxtest.New(
+			GinkgoT(),
+			logging.Module(zap.New(core)),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}),
+			grpcserver.Module,
+			fx.Provide(grpcclient.Provide("test")),
+			fx.Options(options...),
+		)
+	}
+
+	call := func(conn *grpc.ClientConn, value string) (string, error) {
+		reply := new(wrapperspb.StringValue)
+		err := conn.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String(value), reply)
+		return reply.Value, err
+	}
+
+	It("serves services with tracing and logging", func() {
+		var conn *grpc.ClientConn
+		app := newApp(
+			fx.Provide(grpcserver.Provide(&echoDesc, &echo{prefix: "echo: "})),
+			fx.Populate(&conn),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		reply, err := call(conn, "hello")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply).To(Equal("echo: hello"))
+
+		var serverSpan, clientSpan sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			switch span.SpanKind() {
+			case trace.SpanKindServer:
+				serverSpan = span
+			case trace.SpanKindClient:
+				clientSpan = span
+			}
+		}
+		Expect(serverSpan).ToNot(BeNil())
+		Expect(clientSpan).ToNot(BeNil())
+		Expect(serverSpan.Name()).To(Equal("test.Echo/Echo"))
+		Expect(serverSpan.Parent().SpanID()).To(Equal(clientSpan.SpanContext().SpanID()))
+
+		entries := logs.FilterMessage("Handled call").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("method", "/test.Echo/Echo"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("code", "OK"))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("traceID", serverSpan.SpanContext().TraceID().String()))
+	})
+
+	It("supports constructors with dependencies", func() {
+		var conn *grpc.ClientConn
+		app := newApp(
+			fx.Supply("prefix: "),
+			fx.Provide(grpcserver.Provide(&echoDesc, func(prefix string) (echoServer, error) {
+				return &echo{prefix: prefix}, nil
+			})),
+			fx.Populate(&conn),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		reply, err := call(conn, "hello")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply).To(Equal("prefix: hello"))
+	})
+
+	It("recovers panics in handlers", func() {
+		var conn *grpc.ClientConn
+		app := newApp(
+			fx.Provide(grpcserver.Provide(&echoDesc, &echo{})),
+			fx.Populate(&conn),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, err := call(conn, "panic")
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		Expect(logs.FilterMessage("Panic while handling call").Len()).To(Equal(1))
+
+		reply, err := call(conn, "still serving")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reply).To(Equal("still serving"))
+	})
+
+	It("reports readiness via the health service", func() {
+		var conn *grpc.ClientConn
+		var checks health.Checks
+		app := newApp(
+			fx.Provide(grpcserver.Provide(&echoDesc, &echo{})),
+			fx.Populate(&conn, &checks),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		client := grpc_health_v1.NewHealthClient(conn)
+		servingStatus := func(service string) func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+			return func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+				res, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+				if err != nil {
+					return grpc_health_v1.HealthCheckResponse_UNKNOWN
+				}
+				return res.Status
+			}
+		}
+
+		Eventually(servingStatus("")).Should(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+		Eventually(servingStatus("test.Echo")).Should(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+
+		checks.SetMaintenance("testing")
+		// Results of the readiness checks are cached for a second
+		Eventually(servingStatus(""), "3s").Should(Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+
+		checks.ClearMaintenance()
+		Eventually(servingStatus(""), "3s").Should(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+
+	listServices := func(conn *grpc.ClientConn) ([]string, error) {
+		stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = stream.CloseSend() }()
+
+		err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		var services []string
+		for _, service := range res.GetListServicesResponse().GetService() {
+			services = append(services, service.Name)
+		}
+		return services, nil
+	}
+
+	It("does not register reflection by default", func() {
+		var conn *grpc.ClientConn
+		app := newApp(fx.Populate(&conn))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, err := listServices(conn)
+		Expect(status.Code(err)).To(Equal(codes.Unimplemented))
+	})
+
+	It("registers reflection when enabled", func() {
+		GinkgoT().Setenv("GRPC_REFLECTION", "true")
+
+		var conn *grpc.ClientConn
+		app := newApp(
+			fx.Provide(grpcserver.Provide(&echoDesc, &echo{})),
+			fx.Populate(&conn),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		services, err := listServices(conn)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(services).To(ContainElements("test.Echo", "grpc.health.v1.Health"))
+	})
+
+	It("fails if the implementation does not match the service", func() {
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zap.NewNop()),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return sdktrace.NewTracerProvider() }),
+			grpcserver.Module,
+			fx.Provide(grpcserver.Provide(&echoDesc, "not a service")),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("invalid gRPC service test.Echo")))
+	})
+
+	It("fails if a constructor returns an error", func() {
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zap.NewNop()),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return sdktrace.NewTracerProvider() }),
+			grpcserver.Module,
+			fx.Provide(grpcserver.Provide(&echoDesc, func() (echoServer, error) {
+				return nil, errors.New("unavailable")
+			})),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("unavailable")))
+	})
+})
@@ -0,0 +1,115 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/logging"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// unaryLogging logs every unary call once it has been handled.
+func unaryLogging(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+// streamLogging logs every streaming call once it has been handled.
+func streamLogging(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		logCall(stream.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger *zap.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", code.String()),
+		zap.Duration("duration", time.Since(start)),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, zap.String("remoteAddr", p.Addr.String()))
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, zap.String("traceID", spanContext.TraceID().String()))
+	}
+
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	logger = logging.FromContext(ctx, logger)
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		logger.Warn("Handled call", fields...)
+	default:
+		logger.Info("Handled call", fields...)
+	}
+}
+
+// unaryRecovery recovers panics in unary handlers, logging them and
+// returning an internal error instead of crashing the application.
+func unaryRecovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = recovered(ctx, logger, info.FullMethod, value)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// streamRecovery recovers panics in streaming handlers, logging them and
+// returning an internal error instead of crashing the application.
+func streamRecovery(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = recovered(stream.Context(), logger, info.FullMethod, value)
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func recovered(ctx context.Context, logger *zap.Logger, method string, value any) error {
+	err, ok := value.(error)
+	if !ok {
+		err = errors.New(fmt.Sprint(value))
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, "panic: "+err.Error())
+
+	logger.Error(
+		"Panic while handling call",
+		zap.String("method", method),
+		zap.Error(err),
+		zap.String("stack", string(debug.Stack())),
+	)
+
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,186 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Config struct {
+	// Host is the host to bind to, binds to all interfaces if empty
+	Host string `env:"HOST"`
+	// Port is the port to bind to
+	Port int `env:"PORT" envDefault:"9090"`
+
+	// Reflection controls if the reflection service is registered, allowing
+	// tools such as grpcurl to discover services
+	Reflection bool `env:"REFLECTION" envDefault:"false"`
+	// HealthInterval is how often the readiness checks are run to update the
+	// status reported by the gRPC health service
+	HealthInterval time.Duration `env:"HEALTH_INTERVAL" envDefault:"5s"`
+
+	// MaxRecvMsgSize is the maximum size in bytes of a received message
+	MaxRecvMsgSize int `env:"MAX_RECV_MSG_SIZE" envDefault:"4194304"`
+	// MaxSendMsgSize is the maximum size in bytes of a sent message
+	MaxSendMsgSize int `env:"MAX_SEND_MSG_SIZE" envDefault:"2147483647"`
+	// ConnectionTimeout is the maximum time to establish a connection,
+	// including the TLS handshake
+	ConnectionTimeout time.Duration `env:"CONNECTION_TIMEOUT" envDefault:"120s"`
+
+	// DrainDelay is how long the server keeps serving calls after being
+	// marked as not ready during shutdown
+	DrainDelay time.Duration `env:"DRAIN_DELAY" envDefault:"0s"`
+	// ShutdownTimeout is the maximum time to wait for calls in progress to
+	// finish during shutdown, after which they are cancelled
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+
+	// AccessLog controls if every call is logged
+	AccessLog bool `env:"ACCESS_LOG" envDefault:"true"`
+
+	// TLS configures the server to use TLS
+	TLS health.TLSConfig `envPrefix:"TLS_"`
+}
+
+// Module serves the services registered via Provide on a gRPC server that is
+// started and stopped together with the application.
+var Module = fx.Module(
+	"sprout:grpc",
+	fx.Provide(config.Config("GRPC", Config{}), fx.Private),
+	fx.Provide(logging.Logger("grpc"), fx.Private),
+	fx.Provide(newServer),
+	fx.Invoke(func(*Server) {}),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *zap.Logger
+	Config         Config
+	Checks         health.Checks
+	Status         health.Status
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Command        string `name:"service:command" optional:"true"`
+
+	Services []*Service `group:"sprout:grpc:services"`
+}
+
+func newServer(p params) (*Server, error) {
+	tlsConfig, err := p.Config.TLS.ServerConfig(p.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	unary := []grpc.UnaryServerInterceptor{}
+	stream := []grpc.StreamServerInterceptor{}
+	if p.Config.AccessLog {
+		unary = append(unary, unaryLogging(p.Logger))
+		stream = append(stream, streamLogging(p.Logger))
+	}
+	unary = append(unary, unaryRecovery(p.Logger))
+	stream = append(stream, streamRecovery(p.Logger))
+
+	options := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithTracerProvider(p.TracerProvider),
+			otelgrpc.WithMeterProvider(p.MeterProvider),
+			otelgrpc.WithPropagators(otel.GetTextMapPropagator()),
+		)),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.MaxRecvMsgSize(p.Config.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(p.Config.MaxSendMsgSize),
+		grpc.ConnectionTimeout(p.Config.ConnectionTimeout),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(options...)
+
+	services := make([]string, 0, len(p.Services))
+	for _, service := range p.Services {
+		err := register(grpcServer, service)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service.Desc.ServiceName)
+	}
+
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	for _, service := range services {
+		healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+
+	if p.Config.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	s := &Server{
+		logger:       p.Logger,
+		config:       p.Config,
+		grpcServer:   grpcServer,
+		status:       p.Status,
+		healthServer: healthServer,
+		services:     services,
+	}
+
+	if p.Command != "" {
+		p.Logger.Info("Not starting gRPC server while running a command", zap.String("command", p.Command))
+		return s, nil
+	}
+
+	p.Checks.AddReadinessCheck(health.Check{
+		Name:  "grpc-server",
+		Check: s.Check,
+	})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return s.Start()
+		},
+		OnStop: s.Stop,
+	})
+	return s, nil
+}
+
+// register adds a service to the server. The checks are done up front as
+// grpc.Server exits the process for invalid or duplicate services.
+func register(server *grpc.Server, service *Service) error {
+	if service.Impl == nil {
+		return fmt.Errorf("invalid gRPC service %s: implementation is nil", service.Desc.ServiceName)
+	}
+
+	if service.Desc.HandlerType != nil {
+		handlerType := reflect.TypeOf(service.Desc.HandlerType).Elem()
+		if !reflect.TypeOf(service.Impl).Implements(handlerType) {
+			return fmt.Errorf("invalid gRPC service %s: %T does not implement %s", service.Desc.ServiceName, service.Impl, handlerType)
+		}
+	}
+
+	if _, ok := server.GetServiceInfo()[service.Desc.ServiceName]; ok {
+		return fmt.Errorf("gRPC service %s registered more than once", service.Desc.ServiceName)
+	}
+
+	server.RegisterService(service.Desc, service.Impl)
+	return nil
+}
@@ -0,0 +1,153 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Server serves the gRPC services of the application.
+type Server struct {
+	logger     *zap.Logger
+	config     Config
+	grpcServer *grpc.Server
+
+	status       health.Status
+	healthServer *grpchealth.Server
+	services     []string
+
+	listener net.Listener
+	ready    atomic.Bool
+
+	stopHealth context.CancelFunc
+	healthDone chan struct{}
+}
+
+// Start binds to the configured address and starts serving calls.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.Address())
+	if err != nil {
+		return err
+	}
+	s.listener = ln
+
+	s.logger.Info(
+		"Starting gRPC server",
+		zap.String("address", ln.Addr().String()),
+		zap.Bool("tls", s.config.TLS.Enabled()),
+		zap.Bool("reflection", s.config.Reflection),
+	)
+
+	go func() {
+		err := s.grpcServer.Serve(ln)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	s.ready.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopHealth = cancel
+	s.healthDone = make(chan struct{})
+	go s.updateHealth(ctx)
+	return nil
+}
+
+// Stop marks the server as not serving, waits for the drain delay so that
+// load balancers stop sending traffic, and then gracefully stops. Calls that
+// are still in progress when the shutdown timeout expires are cancelled.
+func (s *Server) Stop(ctx context.Context) error {
+	if s.listener == nil {
+		return nil
+	}
+
+	s.ready.Store(false)
+	s.stopHealth()
+	<-s.healthDone
+	s.healthServer.Shutdown()
+
+	if s.config.DrainDelay > 0 {
+		s.logger.Info("Draining gRPC server", zap.Duration("delay", s.config.DrainDelay))
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.config.DrainDelay):
+		}
+	}
+
+	s.logger.Info("Stopping gRPC server")
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return errors.New("gRPC server did not stop gracefully in time, calls were cancelled")
+	}
+}
+
+// Check is used as the readiness check of the server, failing before the
+// server has started and while it is draining.
+func (s *Server) Check(ctx context.Context) error {
+	if !s.ready.Load() {
+		return errors.New("not serving calls")
+	}
+	return nil
+}
+
+// Address returns the address the server binds to.
+func (s *Server) Address() string {
+	return net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+}
+
+// updateHealth periodically runs the readiness checks of the application and
+// reports the result via the gRPC health service, both for the server as a
+// whole and for every registered service.
+func (s *Server) updateHealth(ctx context.Context) {
+	defer close(s.healthDone)
+
+	ticker := time.NewTicker(s.config.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, s.config.HealthInterval)
+		servingStatus := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		if s.status.Ready(checkCtx) {
+			servingStatus = grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.healthServer.SetServingStatus("", servingStatus)
+		for _, service := range s.services {
+			s.healthServer.SetServingStatus(service, servingStatus)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
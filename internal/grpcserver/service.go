@@ -0,0 +1,49 @@
+package grpcserver
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aholstenson/sprout-go/internal/constructor"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+)
+
+// Service is an implementation of a gRPC service together with the
+// description generated for it, such as pb.Greeter_ServiceDesc.
+type Service struct {
+	Desc *grpc.ServiceDesc
+	Impl any
+}
+
+// Provide creates a constructor for use with fx.Provide that registers a
+// service. impl is either the implementation of the service or a constructor
+// that takes dependencies and returns the implementation, optionally with an
+// error.
+func Provide(desc *grpc.ServiceDesc, impl any) any {
+	build := func(impl any) *Service {
+		return &Service{
+			Desc: desc,
+			Impl: impl,
+		}
+	}
+
+	var c any
+	var err error
+	if impl != nil && reflect.TypeOf(impl).Kind() == reflect.Func {
+		c, err = constructor.Constructor(impl, build)
+	} else {
+		c = func() *Service {
+			return build(impl)
+		}
+	}
+
+	if err != nil {
+		// Report the error when the application is created
+		c = func() (*Service, error) {
+			return nil, fmt.Errorf("invalid gRPC service %s: %w", desc.ServiceName, err)
+		}
+	}
+
+	return fx.Annotate(c, fx.ResultTags(`group:"sprout:grpc:services"`))
+}
@@ -1,8 +1,9 @@
 package health
 
-import "github.com/alexliesenfeld/health"
-
-type Check = health.Check
+import (
+	"context"
+	"net/http"
+)
 
 type Checks interface {
 	// AddLivenessCheck adds a check that will run when the service is being
@@ -14,4 +15,41 @@ type Checks interface {
 	// probed for readiness. These checks are exposed via the health server on
 	// the /readyz endpoint.
 	AddReadinessCheck(check Check)
+
+	// SetMaintenance puts the service into maintenance mode, forcing it to
+	// report as not ready until ClearMaintenance is called. The reason is
+	// included in the readiness report.
+	SetMaintenance(reason string)
+
+	// ClearMaintenance takes the service out of maintenance mode.
+	ClearMaintenance()
+
+	// InMaintenance returns if the service is in maintenance mode and the
+	// reason it was put into maintenance.
+	InMaintenance() (bool, string)
+
+	// AddInfo adds information that is included in the liveness and
+	// readiness reports, such as if the service is the leader. The function
+	// is called every time a report is created.
+	AddInfo(name string, info func() any)
+}
+
+type Routes interface {
+	// AddRoute adds an endpoint to the health server, such as diagnostic or
+	// administrative endpoints. Routes are protected by the authentication
+	// configured for the server.
+	AddRoute(pattern string, handler http.Handler)
+}
+
+// Handler serves the probes and routes of the health server. It can be mounted
+// on an application server when the standalone health server is disabled.
+type Handler interface {
+	http.Handler
+}
+
+// Status reports the readiness of the service, making it possible to expose
+// it via protocols other than HTTP.
+type Status interface {
+	// Ready runs the readiness checks and returns if all of them passed.
+	Ready(ctx context.Context) bool
 }
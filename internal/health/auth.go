@@ -0,0 +1,82 @@
+package health
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type AuthConfig struct {
+	// Username enables basic authentication if set
+	Username string `env:"USERNAME"`
+	// Password is the password used for basic authentication
+	Password string `env:"PASSWORD,unset"`
+	// Token enables bearer token authentication if set
+	Token string `env:"TOKEN,unset"`
+}
+
+// Enabled returns if any type of authentication has been configured.
+func (c AuthConfig) Enabled() bool {
+	return c.Username != "" || c.Password != "" || c.Token != ""
+}
+
+func (c AuthConfig) validate() error {
+	if c.Username != "" && c.Password == "" {
+		return errors.New("password must be set when using basic authentication")
+	}
+
+	if c.Username == "" && c.Password != "" {
+		return errors.New("username must be set when using basic authentication")
+	}
+
+	return nil
+}
+
+// requireAuth wraps a handler so that requests must authenticate using either
+// basic authentication or a bearer token. If no authentication is configured
+// the handler is returned as is.
+func requireAuth(config AuthConfig, next http.Handler) http.Handler {
+	if !config.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.authenticate(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if config.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="sprout"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func (c AuthConfig) authenticate(r *http.Request) bool {
+	if c.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && secureEquals(token, c.Token) {
+			return true
+		}
+	}
+
+	if c.Username != "" {
+		username, password, ok := r.BasicAuth()
+		// Compare both values to avoid leaking which one was wrong via timing
+		usernameMatches := secureEquals(username, c.Username)
+		passwordMatches := secureEquals(password, c.Password)
+		if ok && usernameMatches && passwordMatches {
+			return true
+		}
+	}
+
+	return false
+}
+
+func secureEquals(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
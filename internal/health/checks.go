@@ -0,0 +1,330 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alexliesenfeld/health"
+	"go.uber.org/zap"
+)
+
+const (
+	// GroupCritical is a group for checks that are critical for the service
+	// to function.
+	GroupCritical = "critical"
+	// GroupExternal is a group for checks of external dependencies.
+	GroupExternal = "external"
+)
+
+// Check is a health check that can be added to the liveness or readiness
+// probes.
+type Check struct {
+	// Name of the check, must be unique within the probe. Required.
+	Name string
+
+	// Check is the function that checks availability, returning an error if
+	// the checked service is not available. Required.
+	Check func(ctx context.Context) error
+
+	// DependsOn contains the names of checks this check depends on. The
+	// check is skipped if any of its dependencies fail.
+	DependsOn []string
+
+	// Groups are tags such as critical or external. Probes can be filtered
+	// to only run checks in a certain group, e.g. /readyz?group=critical.
+	Groups []string
+
+	// Timeout overrides the timeout of the check if it is smaller than the
+	// timeout configured for the server.
+	Timeout time.Duration
+
+	// MaxTimeInError is the duration the check must fail before it is
+	// considered down.
+	MaxTimeInError time.Duration
+
+	// MaxContiguousFails is the number of times the check must fail in a row
+	// before it is considered down.
+	MaxContiguousFails uint
+
+	// StatusListener is called whenever the status of the check changes.
+	StatusListener func(ctx context.Context, name string, state health.CheckState)
+
+	// Interceptors are executed one after another around the check.
+	Interceptors []health.Interceptor
+
+	// DisablePanicRecovery disables conversion of panics into errors.
+	DisablePanicRecovery bool
+
+	// allGroups is used for internal checks that should be part of every
+	// group, such as maintenance mode.
+	allGroups bool
+}
+
+func (c *Check) inGroup(group string) bool {
+	return c.allGroups || slices.Contains(c.Groups, group)
+}
+
+// skippedError is returned for checks that are skipped because one of their
+// dependencies failed.
+type skippedError struct {
+	dependency string
+}
+
+func (e *skippedError) Error() string {
+	return "skipped, dependency " + e.dependency + " is down"
+}
+
+// probe runs a set of checks, either all of them or only the ones in a
+// certain group.
+type probe struct {
+	checks map[string]*Check
+
+	all     http.Handler
+	checker health.Checker
+	groups  map[string]http.Handler
+}
+
+func newProbe(logger *zap.Logger, timeout time.Duration, checks []Check, options ...health.HandlerOption) (*probe, error) {
+	p := &probe{
+		checks: make(map[string]*Check, len(checks)),
+		groups: make(map[string]http.Handler),
+	}
+
+	for i := range checks {
+		p.checks[checks[i].Name] = &checks[i]
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	p.checker = p.newChecker(logger, timeout, checks)
+	p.all = health.NewHandler(p.checker, options...)
+
+	groups := make(map[string]struct{})
+	for _, check := range checks {
+		for _, group := range check.Groups {
+			groups[group] = struct{}{}
+		}
+	}
+
+	for group := range groups {
+		var groupChecks []Check
+		for _, check := range checks {
+			if check.inGroup(group) {
+				groupChecks = append(groupChecks, check)
+			}
+		}
+
+		p.groups[group] = p.newHandler(logger.With(zap.String("group", group)), timeout, groupChecks, options)
+	}
+
+	return p, nil
+}
+
+// validate checks that all dependencies exist and that there are no cycles.
+func (p *probe) validate() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(p.checks))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("health check %s has a circular dependency", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dependency := range p.checks[name].DependsOn {
+			if _, ok := p.checks[dependency]; !ok {
+				return fmt.Errorf("health check %s depends on unknown check %s", name, dependency)
+			}
+
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for name := range p.checks {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *probe) newHandler(logger *zap.Logger, timeout time.Duration, checks []Check, options []health.HandlerOption) http.Handler {
+	return health.NewHandler(p.newChecker(logger, timeout, checks), options...)
+}
+
+func (p *probe) newChecker(logger *zap.Logger, timeout time.Duration, checks []Check) health.Checker {
+	checker := newChecker(logger, timeout, p.libraryChecks(checks))
+
+	// Run an initial check in the background, replacing the autostart of the
+	// library as checks need to run within an evaluation
+	go checker.Check(withEvaluation(context.Background(), p.checks))
+
+	return checker
+}
+
+// up runs all checks of the probe and returns if they all passed.
+func (p *probe) up(ctx context.Context) bool {
+	result := p.checker.Check(withEvaluation(ctx, p.checks))
+	return result.Status == health.StatusUp
+}
+
+// libraryChecks converts checks into checks for the health library. The
+// functions run via an evaluation so that dependencies run first and only
+// once per probe.
+func (p *probe) libraryChecks(checks []Check) []health.Check {
+	result := make([]health.Check, 0, len(checks))
+	for _, check := range checks {
+		name := check.Name
+		result = append(result, health.Check{
+			Name: name,
+			Check: func(ctx context.Context) error {
+				return evaluationFromContext(ctx, p.checks).run(ctx, name)
+			},
+			Timeout:            check.Timeout,
+			MaxTimeInError:     check.MaxTimeInError,
+			MaxContiguousFails: check.MaxContiguousFails,
+			StatusListener:     check.StatusListener,
+			Interceptors: append(
+				[]health.Interceptor{skippedInterceptor},
+				check.Interceptors...,
+			),
+			DisablePanicRecovery: check.DisablePanicRecovery,
+		})
+	}
+
+	return result
+}
+
+func (p *probe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := p.all
+	if group := r.URL.Query().Get("group"); group != "" {
+		var ok bool
+		handler, ok = p.groups[group]
+		if !ok {
+			http.Error(w, "unknown group", http.StatusNotFound)
+			return
+		}
+	}
+
+	ctx := withEvaluation(r.Context(), p.checks)
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// skippedInterceptor marks checks that were skipped due to a failed
+// dependency as unknown instead of down.
+func skippedInterceptor(next health.InterceptorFunc) health.InterceptorFunc {
+	return func(ctx context.Context, name string, state health.CheckState) health.CheckState {
+		result := next(ctx, name, state)
+
+		var skipped *skippedError
+		if errors.As(result.Result, &skipped) {
+			result.Status = health.StatusUnknown
+		}
+		return result
+	}
+}
+
+type evaluationKey struct{}
+
+// evaluation keeps track of check results during a single probe, making it
+// possible for checks to wait for their dependencies.
+type evaluation struct {
+	checks map[string]*Check
+
+	mutex   sync.Mutex
+	results map[string]*evaluationResult
+}
+
+type evaluationResult struct {
+	done chan struct{}
+	err  error
+}
+
+func withEvaluation(ctx context.Context, checks map[string]*Check) context.Context {
+	return context.WithValue(ctx, evaluationKey{}, &evaluation{
+		checks:  checks,
+		results: make(map[string]*evaluationResult),
+	})
+}
+
+func evaluationFromContext(ctx context.Context, checks map[string]*Check) *evaluation {
+	if e, ok := ctx.Value(evaluationKey{}).(*evaluation); ok {
+		return e
+	}
+
+	return &evaluation{
+		checks:  checks,
+		results: make(map[string]*evaluationResult),
+	}
+}
+
+// run runs the named check, or waits for its result if it is already
+// running as part of this evaluation.
+func (e *evaluation) run(ctx context.Context, name string) error {
+	e.mutex.Lock()
+	result, ok := e.results[name]
+	if !ok {
+		result = &evaluationResult{
+			done: make(chan struct{}),
+		}
+		e.results[name] = result
+	}
+	e.mutex.Unlock()
+
+	if ok {
+		select {
+		case <-result.done:
+			return result.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	result.err = e.execute(ctx, e.checks[name])
+	close(result.done)
+	return result.err
+}
+
+func (e *evaluation) execute(ctx context.Context, check *Check) (err error) {
+	for _, dependency := range check.DependsOn {
+		if e.run(ctx, dependency) != nil {
+			return &skippedError{dependency: dependency}
+		}
+	}
+
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	if !check.DisablePanicRecovery {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+	}
+
+	return check.Check(ctx)
+}
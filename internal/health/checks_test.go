@@ -0,0 +1,185 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Checks", func() {
+	AfterEach(func() {
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	get := func(path string) (int, string) {
+		res, err := http.Get("http://localhost:8088" + path)
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return res.StatusCode, string(body)
+	}
+
+	It("dependent checks are skipped when a dependency fails", func() {
+		var databaseRuns, repositoryRuns atomic.Int32
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddReadinessCheck(health.Check{
+					Name: "repository",
+					Check: func(ctx context.Context) error {
+						repositoryRuns.Add(1)
+						return nil
+					},
+					DependsOn: []string{"database"},
+				})
+				checks.AddReadinessCheck(health.Check{
+					Name: "database",
+					Check: func(ctx context.Context) error {
+						databaseRuns.Add(1)
+						return errors.New("connection refused")
+					},
+				})
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, body := get("/readyz")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(body).To(ContainSubstring("connection refused"))
+		Expect(body).To(ContainSubstring("skipped, dependency database is down"))
+		Expect(databaseRuns.Load()).To(Equal(int32(1)))
+		Expect(repositoryRuns.Load()).To(Equal(int32(0)))
+	})
+
+	It("dependent checks run when dependencies succeed", func() {
+		var repositoryRuns atomic.Int32
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddReadinessCheck(health.Check{
+					Name: "database",
+					Check: func(ctx context.Context) error {
+						return nil
+					},
+				})
+				checks.AddReadinessCheck(health.Check{
+					Name: "repository",
+					Check: func(ctx context.Context) error {
+						repositoryRuns.Add(1)
+						return nil
+					},
+					DependsOn: []string{"database"},
+				})
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, _ := get("/readyz")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(repositoryRuns.Load()).To(Equal(int32(1)))
+	})
+
+	It("probes can be filtered by group", func() {
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddReadinessCheck(health.Check{
+					Name: "database",
+					Check: func(ctx context.Context) error {
+						return nil
+					},
+					Groups: []string{health.GroupCritical},
+				})
+				checks.AddReadinessCheck(health.Check{
+					Name: "recommendations",
+					Check: func(ctx context.Context) error {
+						return errors.New("unavailable")
+					},
+					Groups: []string{health.GroupExternal},
+				})
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, _ := get("/readyz")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+
+		status, body := get("/readyz?group=critical")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).ToNot(ContainSubstring("recommendations"))
+
+		status, _ = get("/readyz?group=external")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+
+		status, _ = get("/readyz?group=unknown")
+		Expect(status).To(Equal(http.StatusNotFound))
+	})
+
+	It("unknown dependencies fail start", func() {
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddReadinessCheck(health.Check{
+					Name: "repository",
+					Check: func(ctx context.Context) error {
+						return nil
+					},
+					DependsOn: []string{"database"},
+				})
+			}),
+		)
+
+		err := app.Start(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("unknown check database")))
+	})
+
+	It("circular dependencies fail start", func() {
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddLivenessCheck(health.Check{
+					Name: "a",
+					Check: func(ctx context.Context) error {
+						return nil
+					},
+					DependsOn: []string{"b"},
+				})
+				checks.AddLivenessCheck(health.Check{
+					Name: "b",
+					Check: func(ctx context.Context) error {
+						return nil
+					},
+					DependsOn: []string{"a"},
+				})
+			}),
+		)
+
+		err := app.Start(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("circular dependency")))
+	})
+})
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maintenance tracks if the service has been put into maintenance mode, in
+// which case it reports as not ready.
+type maintenance struct {
+	logger *zap.Logger
+
+	mutex   sync.RWMutex
+	enabled bool
+	reason  string
+	since   time.Time
+}
+
+type maintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+func (m *maintenance) set(reason string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.enabled {
+		m.since = time.Now()
+	}
+
+	m.enabled = true
+	m.reason = reason
+	m.logger.Warn("Maintenance mode enabled, reporting as not ready", zap.String("reason", reason))
+}
+
+func (m *maintenance) clear() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.enabled {
+		return
+	}
+
+	m.logger.Info("Maintenance mode disabled", zap.Duration("duration", time.Since(m.since)))
+	m.enabled = false
+	m.reason = ""
+	m.since = time.Time{}
+}
+
+func (m *maintenance) status() maintenanceStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if !m.enabled {
+		return maintenanceStatus{}
+	}
+
+	since := m.since
+	return maintenanceStatus{
+		Enabled: true,
+		Reason:  m.reason,
+		Since:   &since,
+	}
+}
+
+// check is a readiness check that fails while maintenance mode is enabled.
+func (m *maintenance) check() Check {
+	return Check{
+		Name:      "maintenance",
+		allGroups: true,
+		Check: func(ctx context.Context) error {
+			status := m.status()
+			if !status.Enabled {
+				return nil
+			}
+
+			if status.Reason == "" {
+				return errors.New("in maintenance")
+			}
+
+			return errors.New("in maintenance: " + status.Reason)
+		},
+	}
+}
+
+// register adds the endpoints used to inspect and toggle maintenance mode.
+func (m *maintenance) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /maintenance", func(w http.ResponseWriter, r *http.Request) {
+		m.writeStatus(w)
+	})
+
+	mux.HandleFunc("POST /maintenance", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if body.Reason == "" {
+			body.Reason = r.URL.Query().Get("reason")
+		}
+
+		m.set(body.Reason)
+		m.writeStatus(w)
+	})
+
+	mux.HandleFunc("DELETE /maintenance", func(w http.ResponseWriter, r *http.Request) {
+		m.clear()
+		m.writeStatus(w)
+	})
+}
+
+func (m *maintenance) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(m.status())
+}
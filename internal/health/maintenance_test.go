@@ -0,0 +1,130 @@
+package health_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Maintenance", func() {
+	AfterEach(func() {
+		// Connections are kept alive between requests, close them so that
+		// the next test does not reuse a connection to a stopped server
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	readiness := func() (int, string) {
+		res, err := http.Get("http://localhost:8088/readyz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return res.StatusCode, string(body)
+	}
+
+	It("readiness is down while in maintenance", func() {
+		var checks health.Checks
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Populate(&checks),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		status, _ := readiness()
+		Expect(status).To(Equal(http.StatusOK))
+
+		checks.SetMaintenance("database upgrade")
+		inMaintenance, reason := checks.InMaintenance()
+		Expect(inMaintenance).To(BeTrue())
+		Expect(reason).To(Equal("database upgrade"))
+
+		Eventually(func(g Gomega) {
+			status, body := readiness()
+			g.Expect(status).To(Equal(http.StatusServiceUnavailable))
+			g.Expect(body).To(ContainSubstring("database upgrade"))
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+
+		checks.ClearMaintenance()
+		inMaintenance, _ = checks.InMaintenance()
+		Expect(inMaintenance).To(BeFalse())
+
+		Eventually(func(g Gomega) {
+			status, _ := readiness()
+			g.Expect(status).To(Equal(http.StatusOK))
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+	})
+
+	It("maintenance can be toggled via endpoint", func() {
+		var checks health.Checks
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Populate(&checks),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Post("http://localhost:8088/maintenance", "application/json", strings.NewReader(`{"reason":"debugging"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		res.Body.Close()
+
+		inMaintenance, reason := checks.InMaintenance()
+		Expect(inMaintenance).To(BeTrue())
+		Expect(reason).To(Equal("debugging"))
+
+		res, err = http.Get("http://localhost:8088/maintenance")
+		Expect(err).ToNot(HaveOccurred())
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		res.Body.Close()
+		Expect(string(body)).To(ContainSubstring(`"enabled":true`))
+
+		req, err := http.NewRequest(http.MethodDelete, "http://localhost:8088/maintenance", nil)
+		Expect(err).ToNot(HaveOccurred())
+		res, err = http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		res.Body.Close()
+
+		inMaintenance, _ = checks.InMaintenance()
+		Expect(inMaintenance).To(BeFalse())
+	})
+
+	It("endpoint requires authentication if configured", func() {
+		t := GinkgoT()
+		t.Setenv("HEALTH_SERVER_AUTH_TOKEN", "secret")
+
+		var checks health.Checks
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Populate(&checks),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Post("http://localhost:8088/maintenance?reason=test", "", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+		res.Body.Close()
+
+		inMaintenance, _ := checks.InMaintenance()
+		Expect(inMaintenance).To(BeFalse())
+	})
+})
@@ -10,5 +10,5 @@ var Module = fx.Module(
 	"sprout:health",
 	fx.Provide(config.Config("HEALTH_SERVER", Config{}), fx.Private),
 	fx.Provide(logging.Logger("health"), fx.Private),
-	fx.Provide(fx.Annotate(NewServer, fx.As(new(Checks)))),
+	fx.Provide(fx.Annotate(NewServer, fx.As(new(Checks)), fx.As(new(Routes)), fx.As(new(Handler)), fx.As(new(Status)))),
 )
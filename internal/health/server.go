@@ -2,9 +2,16 @@ package health
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"io/fs"
+	"maps"
 	"net"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/alexliesenfeld/health"
@@ -13,25 +20,90 @@ import (
 )
 
 type Config struct {
+	// Enabled controls if the standalone server is started. If disabled the
+	// endpoints can be mounted on another server via Handler.
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+
+	// Host is the host to bind to, binds to all interfaces if empty. Use the
+	// format unix:/path/to/socket to bind to a unix socket.
+	Host string `env:"HOST"`
 	// Port is the port to bind to
 	Port int `env:"PORT" envDefault:"8088"`
+
+	// ReadTimeout is the maximum duration for reading a request
+	ReadTimeout time.Duration `env:"READ_TIMEOUT" envDefault:"5s"`
+	// WriteTimeout is the maximum duration before timing out writes of a
+	// response
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" envDefault:"5s"`
+	// IdleTimeout is the maximum amount of time to wait for the next request
+	// on a keep-alive connection
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" envDefault:"5s"`
+	// ShutdownTimeout is the maximum time to wait for the server to shut down
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"5s"`
+	// CheckTimeout is the maximum time a single check may run
+	CheckTimeout time.Duration `env:"CHECK_TIMEOUT" envDefault:"5s"`
+
+	// TLS configures the server to use TLS
+	TLS TLSConfig `envPrefix:"TLS_"`
+	// Auth configures authentication of endpoints that are not probes
+	Auth AuthConfig `envPrefix:"AUTH_"`
 }
 
 type Server struct {
 	logger *zap.Logger
+	config Config
 
 	httpListener net.Listener
 	httpServer   *http.Server
-	httpPort     int
+	certReloader *certReloader
+
+	// handler is the http.Handler serving all endpoints, set when started
+	handler atomic.Pointer[http.Handler]
+	// readiness is the readiness probe, set when started
+	readiness atomic.Pointer[probe]
+
+	maintenance *maintenance
 
 	livenessChecks  []Check
 	readinessChecks []Check
+	routes          []route
+	info            []info
 }
 
-func NewServer(lifecycle fx.Lifecycle, logger *zap.Logger, config Config) Checks {
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+type info struct {
+	name  string
+	value func() any
+}
+
+func NewServer(lifecycle fx.Lifecycle, logger *zap.Logger, config Config) (*Server, error) {
+	if err := config.TLS.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Auth.validate(); err != nil {
+		return nil, err
+	}
+
 	s := &Server{
-		logger:   logger,
-		httpPort: config.Port,
+		logger: logger,
+		config: config,
+		maintenance: &maintenance{
+			logger: logger,
+		},
+	}
+
+	if config.TLS.Enabled() {
+		reloader, err := newCertReloader(logger, config.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		s.certReloader = reloader
 	}
 
 	lifecycle.Append(fx.Hook{
@@ -42,7 +114,7 @@ func NewServer(lifecycle fx.Lifecycle, logger *zap.Logger, config Config) Checks
 			return s.Stop(ctx)
 		},
 	})
-	return s
+	return s, nil
 }
 
 func (s *Server) AddLivenessCheck(check Check) {
@@ -53,37 +125,83 @@ func (s *Server) AddReadinessCheck(check Check) {
 	s.readinessChecks = append(s.readinessChecks, check)
 }
 
+func (s *Server) SetMaintenance(reason string) {
+	s.maintenance.set(reason)
+}
+
+func (s *Server) ClearMaintenance() {
+	s.maintenance.clear()
+}
+
+func (s *Server) InMaintenance() (bool, string) {
+	status := s.maintenance.status()
+	return status.Enabled, status.Reason
+}
+
+func (s *Server) AddInfo(name string, value func() any) {
+	s.info = append(s.info, info{name: name, value: value})
+}
+
+func (s *Server) AddRoute(pattern string, handler http.Handler) {
+	s.routes = append(s.routes, route{pattern: pattern, handler: handler})
+}
+
+// ServeHTTP serves the endpoints of the server, allowing them to be mounted on
+// another server. Requests made before the server is started are rejected.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := s.handler.Load()
+	if handler == nil {
+		http.Error(w, "health server not started", http.StatusServiceUnavailable)
+		return
+	}
+
+	(*handler).ServeHTTP(w, r)
+}
+
+// Ready runs the readiness checks and returns if the service is ready. The
+// service is never ready before the server is started.
+func (s *Server) Ready(ctx context.Context) bool {
+	readiness := s.readiness.Load()
+	if readiness == nil {
+		return false
+	}
+
+	return readiness.up(ctx)
+}
+
 func (s *Server) Start() error {
-	s.logger.Info("Starting health server", zap.Int("port", s.httpPort))
+	handler, err := s.createHandler()
+	if err != nil {
+		return err
+	}
+	s.handler.Store(&handler)
 
-	mux := &http.ServeMux{}
-	mux.HandleFunc(
-		"/healthz",
-		health.NewHandler(newChecker(
-			s.logger.With(zap.String("type", "liveness")),
-			s.livenessChecks,
-		)),
-	)
-	mux.HandleFunc(
-		"/readyz",
-		health.NewHandler(newChecker(
-			s.logger.With(zap.String("type", "readiness")),
-			s.readinessChecks,
-		)),
-	)
+	if !s.config.Enabled {
+		s.logger.Info("Standalone health server disabled, endpoints must be mounted on another server")
+		return nil
+	}
+
+	s.logger.Info("Starting health server", zap.String("address", s.address()), zap.Bool("tls", s.config.TLS.Enabled()))
 
-	ln, err := net.Listen("tcp", ":"+strconv.Itoa(s.httpPort))
+	ln, err := s.listen()
 	if err != nil {
 		return err
 	}
 
+	if s.certReloader != nil {
+		ln = tls.NewListener(ln, &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.certReloader.GetCertificate,
+		})
+	}
+
 	s.httpListener = ln
 
 	s.httpServer = &http.Server{
-		Handler:      mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 5 * time.Second,
-		IdleTimeout:  5 * time.Second,
+		Handler:      handler,
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
 	}
 
 	go func() {
@@ -96,15 +214,115 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+
 	s.logger.Info("Stopping health server")
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
 	defer cancel()
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+
+	// Shutdown only closes the listener if Serve has started using it, close
+	// it directly so the address is released when stopping right after start
+	_ = s.httpListener.Close()
+	return err
+}
+
+// createHandler creates the handler for all endpoints of the server.
+func (s *Server) createHandler() (http.Handler, error) {
+	liveness, err := newProbe(
+		s.logger.With(zap.String("type", "liveness")),
+		s.config.CheckTimeout,
+		s.livenessChecks,
+		health.WithMiddleware(s.infoMiddleware),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness, err := newProbe(
+		s.logger.With(zap.String("type", "readiness")),
+		s.config.CheckTimeout,
+		append([]Check{s.maintenance.check()}, s.readinessChecks...),
+		health.WithMiddleware(s.infoMiddleware),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.readiness.Store(readiness)
+
+	// Probes are always available without authentication, other endpoints
+	// are registered on a separate mux that requires authentication
+	adminMux := &http.ServeMux{}
+
+	mux := &http.ServeMux{}
+	mux.Handle("/healthz", liveness)
+	mux.Handle("/readyz", readiness)
+	mux.Handle("/", requireAuth(s.config.Auth, adminMux))
+
+	s.maintenance.register(adminMux)
+
+	for _, route := range s.routes {
+		adminMux.Handle(route.pattern, route.handler)
+	}
+
+	return mux, nil
+}
+
+// infoMiddleware adds the information registered via AddInfo to reports.
+func (s *Server) infoMiddleware(next health.MiddlewareFunc) health.MiddlewareFunc {
+	return func(r *http.Request) health.CheckerResult {
+		result := next(r)
+		if len(s.info) == 0 {
+			return result
+		}
+
+		// The info of the result is shared between reports, copy it before
+		// adding to it
+		values := make(map[string]any, len(result.Info)+len(s.info))
+		maps.Copy(values, result.Info)
+		for _, info := range s.info {
+			values[info.name] = info.value()
+		}
+
+		result.Info = values
+		return result
+	}
+}
+
+// address returns the address the server binds to, used for logging.
+func (s *Server) address() string {
+	if strings.HasPrefix(s.config.Host, "unix:") {
+		return s.config.Host
+	}
+
+	return net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	path, isUnix := strings.CutPrefix(s.config.Host, "unix:")
+	if !isUnix {
+		return net.Listen("tcp", s.address())
+	}
+
+	// Remove a stale socket left behind by a previous process
+	info, err := os.Stat(path)
+	if err == nil && info.Mode().Type() == fs.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
 }
 
-func newChecker(logger *zap.Logger, checks []Check) health.Checker {
+func newChecker(logger *zap.Logger, timeout time.Duration, checks []health.Check) health.Checker {
 	options := []health.CheckerOption{
-		health.WithTimeout(5 * time.Second),
+		health.WithTimeout(timeout),
+		health.WithDisabledAutostart(),
 		health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
 			switch state.Status {
 			case health.StatusDown:
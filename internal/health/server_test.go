@@ -3,7 +3,12 @@ package health_test
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 
 	"github.com/aholstenson/sprout-go/internal/health"
 	"github.com/aholstenson/sprout-go/internal/logging"
@@ -115,4 +120,282 @@ var _ = Describe("Health", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
 	})
+
+	It("status reports readiness once started", func() {
+		var status health.Status
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Populate(&status),
+		)
+		Expect(status.Ready(context.Background())).To(BeFalse())
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(status.Ready(context.Background())).To(BeTrue())
+	})
+
+	It("status reports failing readiness checks", func() {
+		var status health.Status
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddReadinessCheck(health.Check{
+					Name: "test",
+					Check: func(ctx context.Context) error {
+						return errors.New("failed")
+					},
+				})
+			}),
+			fx.Populate(&status),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(status.Ready(context.Background())).To(BeFalse())
+	})
+
+	It("server can bind to a specific host", func() {
+		t := GinkgoT()
+		t.Setenv("HEALTH_SERVER_HOST", "127.0.0.1")
+		t.Setenv("HEALTH_SERVER_PORT", "8089")
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				// Do nothing, only here to make server always start
+			}),
+		)
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Get("http://127.0.0.1:8089/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("server can bind to a unix socket", func() {
+		t := GinkgoT()
+		socket := filepath.Join(t.TempDir(), "health.sock")
+		t.Setenv("HEALTH_SERVER_HOST", "unix:"+socket)
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				// Do nothing, only here to make server always start
+			}),
+		)
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		}
+
+		res, err := client.Get("http://unix/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("standalone server can be disabled and mounted via handler", func() {
+		t := GinkgoT()
+		t.Setenv("HEALTH_SERVER_ENABLED", "false")
+
+		var handler health.Handler
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Populate(&handler),
+		)
+
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, err := http.Get("http://localhost:8088/healthz")
+		Expect(err).To(HaveOccurred())
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		res, err := http.Get(server.URL + "/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		res, err = http.Get(server.URL + "/readyz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("routes can be added", func() {
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(routes health.Routes) {
+				routes.AddRoute("/custom", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusAccepted)
+				}))
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Get("http://localhost:8088/custom")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusAccepted))
+	})
+
+	It("info is included in reports", func() {
+		var value atomic.Value
+		value.Store("first")
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				checks.AddInfo("test", value.Load)
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		for _, path := range []string{"/healthz", "/readyz"} {
+			res, err := http.Get("http://localhost:8088" + path)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body).To(ContainSubstring(`"info":{"test":"first"}`))
+		}
+
+		value.Store("second")
+		res, err := http.Get("http://localhost:8088/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body).To(ContainSubstring(`"info":{"test":"second"}`))
+	})
+
+	Describe("Authentication", func() {
+		get := func(path string, modify func(req *http.Request)) *http.Response {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8088"+path, nil)
+			Expect(err).ToNot(HaveOccurred())
+			modify(req)
+
+			res, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			return res
+		}
+
+		It("probes do not require authentication", func() {
+			t := GinkgoT()
+			t.Setenv("HEALTH_SERVER_AUTH_TOKEN", "secret")
+			app := fxtest.New(
+				t,
+				logging.Module(zaptest.NewLogger(GinkgoT())),
+				health.Module,
+				fx.Invoke(func(checks health.Checks) {
+					// Do nothing, only here to make server always start
+				}),
+			)
+
+			app.RequireStart()
+			defer app.RequireStop()
+
+			res := get("/healthz", func(req *http.Request) {})
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+			res = get("/readyz", func(req *http.Request) {})
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("other endpoints require bearer token", func() {
+			t := GinkgoT()
+			t.Setenv("HEALTH_SERVER_AUTH_TOKEN", "secret")
+			app := fxtest.New(
+				t,
+				logging.Module(zaptest.NewLogger(GinkgoT())),
+				health.Module,
+				fx.Invoke(func(checks health.Checks) {
+					// Do nothing, only here to make server always start
+				}),
+			)
+
+			app.RequireStart()
+			defer app.RequireStop()
+
+			res := get("/unknown", func(req *http.Request) {})
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+
+			res = get("/unknown", func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer wrong")
+			})
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+
+			res = get("/unknown", func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer secret")
+			})
+			Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("other endpoints require basic authentication", func() {
+			t := GinkgoT()
+			t.Setenv("HEALTH_SERVER_AUTH_USERNAME", "admin")
+			t.Setenv("HEALTH_SERVER_AUTH_PASSWORD", "secret")
+			app := fxtest.New(
+				t,
+				logging.Module(zaptest.NewLogger(GinkgoT())),
+				health.Module,
+				fx.Invoke(func(checks health.Checks) {
+					// Do nothing, only here to make server always start
+				}),
+			)
+
+			app.RequireStart()
+			defer app.RequireStop()
+
+			res := get("/unknown", func(req *http.Request) {})
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(res.Header.Get("WWW-Authenticate")).To(ContainSubstring("Basic"))
+
+			res = get("/unknown", func(req *http.Request) {
+				req.SetBasicAuth("admin", "wrong")
+			})
+			Expect(res.StatusCode).To(Equal(http.StatusUnauthorized))
+
+			res = get("/unknown", func(req *http.Request) {
+				req.SetBasicAuth("admin", "secret")
+			})
+			Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("basic authentication requires a password", func() {
+			t := GinkgoT()
+			t.Setenv("HEALTH_SERVER_AUTH_USERNAME", "admin")
+			app := fx.New(
+				fx.NopLogger,
+				logging.Module(zaptest.NewLogger(GinkgoT())),
+				health.Module,
+				fx.Invoke(func(checks health.Checks) {
+					// Do nothing, only here to make server always start
+				}),
+			)
+
+			Expect(app.Err()).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type TLSConfig struct {
+	// CertFile is the path to a PEM encoded certificate, enables TLS if set
+	CertFile string `env:"CERT_FILE"`
+	// KeyFile is the path to the PEM encoded private key of the certificate
+	KeyFile string `env:"KEY_FILE"`
+}
+
+// Enabled returns if TLS has been configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+func (c TLSConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("both certificate and key file must be set to enable TLS")
+	}
+
+	return nil
+}
+
+// certReloader keeps a certificate loaded from disk and reloads it when the
+// certificate or key file changes. This allows certificates to be rotated
+// without restarting the service.
+type certReloader struct {
+	logger *zap.Logger
+
+	certFile string
+	keyFile  string
+
+	mutex       sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func newCertReloader(logger *zap.Logger, config TLSConfig) (*certReloader, error) {
+	r := &certReloader{
+		logger:   logger,
+		certFile: config.CertFile,
+		keyFile:  config.KeyFile,
+	}
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.load(certModTime, keyModTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, reloading it if the files
+// on disk have been modified since it was last loaded.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		r.logger.Warn("Unable to check TLS certificate for changes, using current certificate", zap.Error(err))
+		return r.cert, nil
+	}
+
+	if certModTime.Equal(r.certModTime) && keyModTime.Equal(r.keyModTime) {
+		return r.cert, nil
+	}
+
+	err = r.load(certModTime, keyModTime)
+	if err != nil {
+		r.logger.Warn("Unable to reload TLS certificate, using current certificate", zap.Error(err))
+		return r.cert, nil
+	}
+
+	r.logger.Info("Reloaded TLS certificate", zap.String("certFile", r.certFile))
+	return r.cert, nil
+}
+
+func (r *certReloader) load(certModTime time.Time, keyModTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	return nil
+}
+
+func (r *certReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// ServerConfig returns a TLS configuration for servers that reloads the
+// certificate when it changes on disk, or nil if TLS is not enabled.
+func (c TLSConfig) ServerConfig(logger *zap.Logger) (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	reloader, err := newCertReloader(logger, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package health_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("TLS", func() {
+	var certFile, keyFile string
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		certFile = filepath.Join(dir, "tls.crt")
+		keyFile = filepath.Join(dir, "tls.key")
+	})
+
+	// servedSerial connects to the health server and returns the serial
+	// number of the certificate it presents.
+	servedSerial := func() *big.Int {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, //nolint:gosec
+				},
+			},
+		}
+
+		res, err := client.Get("https://localhost:8088/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		return res.TLS.PeerCertificates[0].SerialNumber
+	}
+
+	It("server can serve TLS", func() {
+		t := GinkgoT()
+		writeCertificate(certFile, keyFile, 1, time.Now())
+		t.Setenv("HEALTH_SERVER_TLS_CERT_FILE", certFile)
+		t.Setenv("HEALTH_SERVER_TLS_KEY_FILE", keyFile)
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				// Do nothing, only here to make server always start
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(servedSerial().Int64()).To(Equal(int64(1)))
+	})
+
+	It("server reloads certificate when files change", func() {
+		t := GinkgoT()
+		writeCertificate(certFile, keyFile, 1, time.Now().Add(-time.Minute))
+		t.Setenv("HEALTH_SERVER_TLS_CERT_FILE", certFile)
+		t.Setenv("HEALTH_SERVER_TLS_KEY_FILE", keyFile)
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				// Do nothing, only here to make server always start
+			}),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(servedSerial().Int64()).To(Equal(int64(1)))
+
+		writeCertificate(certFile, keyFile, 2, time.Now())
+		Expect(servedSerial().Int64()).To(Equal(int64(2)))
+	})
+
+	It("server fails if certificate can not be loaded", func() {
+		t := GinkgoT()
+		t.Setenv("HEALTH_SERVER_TLS_CERT_FILE", certFile)
+		t.Setenv("HEALTH_SERVER_TLS_KEY_FILE", keyFile)
+
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Invoke(func(checks health.Checks) {
+				// Do nothing, only here to make server always start
+			}),
+		)
+
+		Expect(app.Err()).To(HaveOccurred())
+	})
+})
+
+// writeCertificate writes a self-signed certificate with the given serial
+// number, setting the modification time of the files to modTime.
+func writeCertificate(certFile string, keyFile string, serial int64, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	Expect(err).ToNot(HaveOccurred())
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.Chtimes(certFile, modTime, modTime)).To(Succeed())
+	Expect(os.Chtimes(keyFile, modTime, modTime)).To(Succeed())
+}
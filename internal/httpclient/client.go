@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type params struct {
+	fx.In
+
+	Logger         *zap.Logger `name:"logging.zap"`
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Validation     *config.Validation `optional:"true"`
+	Overrides      config.Overrides   `optional:"true"`
+}
+
+// Provide creates a constructor for use with fx.Provide that creates a
+// *http.Client configured via environment variables prefixed with
+// HTTP_CLIENT_<NAME>_.
+func Provide(name string) any {
+	return func(p params) (*http.Client, error) {
+		logger := logging.CreateLogger(p.Logger, []string{"http", "client", name})
+
+		cfg, err := config.Load(configPrefix(name), Config{}, config.In{
+			Logger:     logging.CreateLogger(p.Logger, []string{"config"}),
+			Validation: p.Validation,
+			Overrides:  p.Overrides,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return newClient(name, cfg, logger, p.TracerProvider, p.MeterProvider)
+	}
+}
+
+func newClient(
+	name string,
+	cfg Config,
+	logger *zap.Logger,
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	var transport http.RoundTripper = &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	transport = &loggingTransport{
+		logger: logger,
+		next:   transport,
+	}
+
+	// Every attempt gets its own span, so retries wrap the instrumentation
+	transport = otelhttp.NewTransport(
+		transport,
+		otelhttp.WithTracerProvider(tracerProvider),
+		otelhttp.WithMeterProvider(meterProvider),
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			return name + " " + r.Method
+		}),
+	)
+
+	if cfg.RetryMax > 0 {
+		transport = &retryTransport{
+			logger:         logger,
+			next:           transport,
+			maxRetries:     cfg.RetryMax,
+			initialBackoff: cfg.RetryInitialBackoff,
+			maxBackoff:     cfg.RetryMaxBackoff,
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}, nil
+}
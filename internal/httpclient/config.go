@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Config struct {
+	// Timeout is the maximum duration of a request, including retries
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"30s"`
+	// DialTimeout is the maximum duration for establishing a connection
+	DialTimeout time.Duration `env:"DIAL_TIMEOUT" envDefault:"5s"`
+	// TLSHandshakeTimeout is the maximum duration of the TLS handshake
+	TLSHandshakeTimeout time.Duration `env:"TLS_HANDSHAKE_TIMEOUT" envDefault:"10s"`
+	// ResponseHeaderTimeout is the maximum time to wait for response
+	// headers, 0 means no limit
+	ResponseHeaderTimeout time.Duration `env:"RESPONSE_HEADER_TIMEOUT" envDefault:"0s"`
+	// IdleConnTimeout is how long idle connections are kept open
+	IdleConnTimeout time.Duration `env:"IDLE_CONN_TIMEOUT" envDefault:"90s"`
+
+	// MaxIdleConns is the maximum number of idle connections across all hosts
+	MaxIdleConns int `env:"MAX_IDLE_CONNS" envDefault:"100"`
+	// MaxIdleConnsPerHost is the maximum number of idle connections per host
+	MaxIdleConnsPerHost int `env:"MAX_IDLE_CONNS_PER_HOST" envDefault:"10"`
+	// MaxConnsPerHost limits the number of connections per host, 0 means no
+	// limit
+	MaxConnsPerHost int `env:"MAX_CONNS_PER_HOST" envDefault:"0"`
+
+	// Proxy is the URL of a proxy to use, read from HTTP_PROXY, HTTPS_PROXY
+	// and NO_PROXY if not set
+	Proxy string `env:"PROXY"`
+
+	// RetryMax is the maximum number of retries of idempotent requests, 0
+	// disables retries
+	RetryMax int `env:"RETRY_MAX" envDefault:"0"`
+	// RetryInitialBackoff is the delay before the first retry, doubled for
+	// every retry
+	RetryInitialBackoff time.Duration `env:"RETRY_INITIAL_BACKOFF" envDefault:"100ms"`
+	// RetryMaxBackoff is the maximum delay between retries
+	RetryMaxBackoff time.Duration `env:"RETRY_MAX_BACKOFF" envDefault:"5s"`
+}
+
+// configPrefix returns the environment variable prefix for a client, such as
+// HTTP_CLIENT_PAYMENT_API for payment-api.
+func configPrefix(name string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+
+	return "HTTP_CLIENT_" + normalized
+}
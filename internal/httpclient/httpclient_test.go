@@ -0,0 +1,136 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/httpclient"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("HTTP client", func() {
+	var recorder *tracetest.SpanRecorder
+	var tracerProvider *sdktrace.TracerProvider
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+
+	newClient := func(name string) *http.Client {
+		var client *http.Client
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return tracerProvider }),
+			fx.Provide(httpclient.Provide(name)),
+			fx.Populate(&client),
+		)
+		app.RequireStart()
+		DeferCleanup(app.RequireStop)
+		return client
+	}
+
+	It("is configured from the environment", func() {
+		GinkgoT().Setenv("HTTP_CLIENT_PAYMENT_API_TIMEOUT", "5s")
+
+		client := newClient("payment-api")
+		Expect(client.Timeout).To(Equal(5 * time.Second))
+	})
+
+	It("propagates the trace context", func() {
+		var traceparent atomic.Value
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparent.Store(r.Header.Get("traceparent"))
+		}))
+		defer server.Close()
+
+		client := newClient("test")
+
+		ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		res.Body.Close()
+		span.End()
+
+		Expect(traceparent.Load()).To(ContainSubstring(span.SpanContext().TraceID().String()))
+		Expect(recorder.Ended()).To(ContainElement(WithTransform(sdktrace.ReadOnlySpan.Name, Equal("test GET"))))
+	})
+
+	Describe("retries", func() {
+		var attempts atomic.Int32
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			GinkgoT().Setenv("HTTP_CLIENT_TEST_RETRY_MAX", "2")
+			GinkgoT().Setenv("HTTP_CLIENT_TEST_RETRY_INITIAL_BACKOFF", "1ms")
+
+			attempts.Store(0)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(server.Close)
+		})
+
+		It("retries idempotent requests", func() {
+			client := newClient("test")
+
+			res, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(attempts.Load()).To(Equal(int32(3)))
+		})
+
+		It("resends the body", func() {
+			client := newClient("test")
+
+			req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("body"))
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(attempts.Load()).To(Equal(int32(3)))
+		})
+
+		It("does not retry other requests", func() {
+			client := newClient("test")
+
+			res, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+			Expect(err).ToNot(HaveOccurred())
+			res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(attempts.Load()).To(Equal(int32(1)))
+		})
+	})
+})
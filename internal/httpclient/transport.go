@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// loggingTransport logs requests and responses at debug level.
+type loggingTransport struct {
+	logger *zap.Logger
+	next   http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.logger.Core().Enabled(zap.DebugLevel) {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("host", req.URL.Host),
+		zap.String("path", req.URL.Path),
+	}
+
+	res, err := t.next.RoundTrip(req)
+	fields = append(fields, zap.Duration("duration", time.Since(start)))
+	if err != nil {
+		t.logger.Debug("Request failed", append(fields, zap.Error(err))...)
+		return nil, err
+	}
+
+	t.logger.Debug("Received response", append(fields,
+		zap.Int("status", res.StatusCode),
+		zap.Int64("contentLength", res.ContentLength),
+	)...)
+	return res, nil
+}
+
+// retryTransport retries idempotent requests that fail with a network error
+// or a status indicating that the server is temporarily unavailable.
+type retryTransport struct {
+	logger *zap.Logger
+	next   http.RoundTripper
+
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !canRetry(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.initialBackoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		res, err := t.next.RoundTrip(req)
+		if attempt >= t.maxRetries || !shouldRetry(res, err) {
+			return res, err
+		}
+
+		delay := backoff
+		if res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+				delay = min(retryAfter, t.maxBackoff)
+			}
+
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			_ = res.Body.Close()
+		}
+
+		t.logger.Debug(
+			"Retrying request",
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.String("path", req.URL.Path),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, t.maxBackoff)
+	}
+}
+
+// canRetry checks if the request is idempotent and its body can be sent
+// again.
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,17 @@
+package httpserver_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPServer(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8094")
+	t.Setenv("HTTP_PORT", "18080")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Server Suite")
+}
@@ -0,0 +1,186 @@
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/httpserver"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("HTTP server", func() {
+	var recorder *tracetest.SpanRecorder
+	var logs *observer.ObservedLogs
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+	})
+
+	AfterEach(func() {
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	newApp := func(options ...fx.Option) *fxtest.App {
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+
+		return fxtest.New(
+			GinkgoT(),
+			logging.Module(zap.New(core)),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}),
+			httpserver.Module,
+			fx.Options(options...),
+		)
+	}
+
+	get := func(path string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost:18080"+path, nil)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return res, string(body)
+	}
+
+	It("serves routes with tracing, request IDs and access logs", func() {
+		app := newApp(fx.Provide(httpserver.Provide("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("user " + r.PathValue("id") + " " + httpserver.RequestID(r.Context())))
+		})))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, body := get("/users/1")
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		requestID := res.Header.Get(httpserver.RequestIDHeader)
+		Expect(requestID).ToNot(BeEmpty())
+		Expect(body).To(Equal("user 1 " + requestID))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("GET /users/{id}"))
+
+		entries := logs.FilterMessage("Served request").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("status", int64(http.StatusOK)))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("requestID", requestID))
+	})
+
+	It("keeps request IDs sent by the client", func() {
+		app := newApp(fx.Provide(httpserver.Provide("GET /", http.NotFoundHandler())))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, _ := get("/", httpserver.RequestIDHeader, "client-id")
+		Expect(res.Header.Get(httpserver.RequestIDHeader)).To(Equal("client-id"))
+	})
+
+	It("adds baggage sent by the client to access logs", func() {
+		previous := otel.GetTextMapPropagator()
+		otel.SetTextMapPropagator(propagation.Baggage{})
+		defer otel.SetTextMapPropagator(previous)
+
+		app := newApp(fx.Provide(httpserver.Provide("GET /", http.NotFoundHandler())))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		get("/", "baggage", "tenant=acme")
+
+		entries := logs.FilterMessage("Served request").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("baggage", map[string]any{"tenant": "acme"}))
+	})
+
+	It("supports constructors with dependencies", func() {
+		type Greeting string
+
+		app := newApp(
+			fx.Supply(Greeting("hello")),
+			fx.Provide(httpserver.Provide("GET /greet", func(greeting Greeting) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(greeting))
+				})
+			})),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, body := get("/greet")
+		Expect(body).To(Equal("hello"))
+	})
+
+	It("recovers from panics", func() {
+		app := newApp(fx.Provide(httpserver.Provide("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, _ := get("/panic")
+		Expect(res.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		entries := logs.FilterMessage("Panic while serving request").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()["stack"]).To(ContainSubstring("goroutine"))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+	})
+
+	It("reports readiness", func() {
+		app := newApp(fx.Provide(httpserver.Provide("GET /", http.NotFoundHandler())))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		res, err := http.Get("http://localhost:8094/readyz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(string(body)).To(ContainSubstring("http-server"))
+	})
+
+	It("fails for conflicting routes", func() {
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zap.NewNop()),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider { return sdktrace.NewTracerProvider() }),
+			httpserver.Module,
+			fx.Provide(httpserver.Provide("GET /", http.NotFoundHandler())),
+			fx.Provide(httpserver.Provide("GET /", http.NotFoundHandler())),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("invalid route GET /")))
+	})
+})
@@ -0,0 +1,176 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header used to receive and return request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request being served with the context, or
+// an empty string if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID uses the request ID sent by the client if valid, otherwise
+// a new one is generated. The ID is returned in the response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// withAccessLog logs every request once it has been served.
+func withAccessLog(logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics := httpsnoop.CaptureMetrics(next, w, r)
+
+		fields := []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", metrics.Code),
+			zap.Int64("bytes", metrics.Written),
+			zap.Duration("duration", metrics.Duration),
+			zap.String("remoteAddr", r.RemoteAddr),
+			zap.String("requestID", RequestID(r.Context())),
+		}
+
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			fields = append(fields, zap.String("traceID", spanContext.TraceID().String()))
+		}
+
+		logging.FromContext(r.Context(), logger).Info("Served request", fields...)
+	})
+}
+
+// withRecovery recovers panics in handlers, logging them and responding with
+// an internal server error instead of closing the connection.
+func withRecovery(logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		written := false
+		w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+			WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+				return func(code int) {
+					written = true
+					next(code)
+				}
+			},
+			Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+				return func(b []byte) (int, error) {
+					written = true
+					return next(b)
+				}
+			},
+		})
+
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+
+			if value == http.ErrAbortHandler { //nolint:errorlint
+				// Used to abort the response, let net/http handle it
+				panic(value)
+			}
+
+			err, ok := value.(error)
+			if !ok {
+				err = errors.New(fmt.Sprint(value))
+			}
+
+			span := trace.SpanFromContext(r.Context())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "panic: "+err.Error())
+
+			logger.Error(
+				"Panic while serving request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("requestID", RequestID(r.Context())),
+				zap.Error(err),
+				zap.String("stack", string(debug.Stack())),
+			)
+
+			if !written {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRoute names the span and metrics of a request after the pattern of the
+// route that matched it.
+func withRoute(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Pattern
+		if route == "" {
+			route = pattern
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + routePath(route))
+		span.SetAttributes(semconv.HTTPRoute(routePath(route)))
+
+		if labeler, ok := otelhttp.LabelerFromContext(r.Context()); ok {
+			labeler.Add(semconv.HTTPRoute(routePath(route)))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routePath removes the method and host from a pattern, leaving the path.
+func routePath(pattern string) string {
+	for i, c := range pattern {
+		if c == '/' {
+			return pattern[i:]
+		}
+	}
+	return pattern
+}
@@ -0,0 +1,142 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Host is the host to bind to, binds to all interfaces if empty
+	Host string `env:"HOST"`
+	// Port is the port to bind to
+	Port int `env:"PORT" envDefault:"8080"`
+
+	// ReadHeaderTimeout is the maximum duration for reading request headers
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" envDefault:"10s"`
+	// ReadTimeout is the maximum duration for reading a request
+	ReadTimeout time.Duration `env:"READ_TIMEOUT" envDefault:"30s"`
+	// WriteTimeout is the maximum duration before timing out writes of a
+	// response
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
+	// IdleTimeout is the maximum amount of time to wait for the next request
+	// on a keep-alive connection
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" envDefault:"2m"`
+	// DrainDelay is how long the server keeps serving requests after being
+	// marked as not ready during shutdown
+	DrainDelay time.Duration `env:"DRAIN_DELAY" envDefault:"0s"`
+	// ShutdownTimeout is the maximum time to wait for requests in progress
+	// to finish during shutdown
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+
+	// AccessLog controls if every request is logged
+	AccessLog bool `env:"ACCESS_LOG" envDefault:"true"`
+
+	// TLS configures the server to use TLS
+	TLS health.TLSConfig `envPrefix:"TLS_"`
+}
+
+// Module serves the routes registered via Provide on a HTTP server that is
+// started and stopped together with the application.
+var Module = fx.Module(
+	"sprout:http",
+	fx.Provide(config.Config("HTTP", Config{}), fx.Private),
+	fx.Provide(logging.Logger("http"), fx.Private),
+	fx.Provide(newServer),
+	fx.Invoke(func(*Server) {}),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *zap.Logger
+	Config         Config
+	Checks         health.Checks
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Command        string `name:"service:command" optional:"true"`
+
+	Routes []*Route `group:"sprout:http:routes"`
+}
+
+func newServer(p params) (*Server, error) {
+	tlsConfig, err := p.Config.TLS.ServerConfig(p.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range p.Routes {
+		err := register(mux, route)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var handler http.Handler = withRecovery(p.Logger, mux)
+	if p.Config.AccessLog {
+		handler = withAccessLog(p.Logger, handler)
+	}
+	handler = withRequestID(handler)
+	handler = otelhttp.NewHandler(
+		handler,
+		"HTTP",
+		otelhttp.WithTracerProvider(p.TracerProvider),
+		otelhttp.WithMeterProvider(p.MeterProvider),
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			// Renamed after the route once it has been matched
+			return r.Method
+		}),
+	)
+
+	s := &Server{
+		logger:    p.Logger,
+		config:    p.Config,
+		tlsConfig: tlsConfig,
+		handler:   handler,
+	}
+
+	if p.Command != "" {
+		p.Logger.Info("Not starting HTTP server while running a command", zap.String("command", p.Command))
+		return s, nil
+	}
+
+	p.Checks.AddReadinessCheck(health.Check{
+		Name:  "http-server",
+		Check: s.Check,
+	})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return s.Start()
+		},
+		OnStop: s.Stop,
+	})
+	return s, nil
+}
+
+// register adds a route to the mux, converting the panic of http.ServeMux
+// for invalid or conflicting patterns into an error.
+func register(mux *http.ServeMux, route *Route) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = fmt.Errorf("invalid route %s: %v", route.Pattern, value)
+		}
+	}()
+
+	mux.Handle(route.Pattern, withRoute(route.Pattern, route.Handler))
+	return nil
+}
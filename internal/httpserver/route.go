@@ -0,0 +1,41 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aholstenson/sprout-go/internal/constructor"
+	"go.uber.org/fx"
+)
+
+// Route is a handler served for a pattern, using the syntax of
+// http.ServeMux such as "GET /users/{id}".
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Provide creates a constructor for use with fx.Provide that registers a
+// route. handler is either a http.Handler, a handler function or a
+// constructor that takes dependencies and returns a http.Handler, optionally
+// with an error.
+func Provide(pattern string, handler any) any {
+	if f, ok := handler.(func(http.ResponseWriter, *http.Request)); ok {
+		handler = http.HandlerFunc(f)
+	}
+
+	c, err := constructor.Wrap(handler, func(h http.Handler) *Route {
+		return &Route{
+			Pattern: pattern,
+			Handler: h,
+		}
+	})
+	if err != nil {
+		// Report the error when the application is created
+		c = func() (*Route, error) {
+			return nil, fmt.Errorf("invalid route %s: %w", pattern, err)
+		}
+	}
+
+	return fx.Annotate(c, fx.ResultTags(`group:"sprout:http:routes"`))
+}
@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Server serves the routes of the application.
+type Server struct {
+	logger    *zap.Logger
+	config    Config
+	tlsConfig *tls.Config
+	handler   http.Handler
+
+	listener   net.Listener
+	httpServer *http.Server
+
+	ready atomic.Bool
+}
+
+// Start binds to the configured address and starts serving requests.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.Address())
+	if err != nil {
+		return err
+	}
+
+	if s.tlsConfig != nil {
+		ln = tls.NewListener(ln, s.tlsConfig)
+	}
+
+	s.listener = ln
+	s.httpServer = &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+		ErrorLog:          zap.NewStdLog(s.logger),
+	}
+
+	s.logger.Info("Starting HTTP server", zap.String("address", ln.Addr().String()), zap.Bool("tls", s.tlsConfig != nil))
+
+	go func() {
+		err := s.httpServer.Serve(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	s.ready.Store(true)
+	return nil
+}
+
+// Stop marks the server as not ready, waits for the drain delay so that load
+// balancers stop sending traffic, and then gracefully shuts down.
+func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+
+	s.ready.Store(false)
+
+	if s.config.DrainDelay > 0 {
+		s.logger.Info("Draining HTTP server", zap.Duration("delay", s.config.DrainDelay))
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.config.DrainDelay):
+		}
+	}
+
+	s.logger.Info("Stopping HTTP server")
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
+	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
+
+	// Shutdown only closes the listener if Serve has started using it
+	_ = s.listener.Close()
+	return err
+}
+
+// Check is used as the readiness check of the server, failing before the
+// server has started and while it is draining.
+func (s *Server) Check(ctx context.Context) error {
+	if !s.ready.Load() {
+		return errors.New("not serving requests")
+	}
+	return nil
+}
+
+// Address returns the address the server binds to.
+func (s *Server) Address() string {
+	return net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+}
@@ -0,0 +1,277 @@
+package leader
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Leadership reports if this instance is the leader among the replicas of
+// the service.
+type Leadership interface {
+	// IsLeader returns if this instance is currently the leader.
+	IsLeader() bool
+
+	// OnElected registers a function that is called when this instance
+	// becomes the leader, with a context that is canceled when leadership
+	// is lost. The function is called immediately if this instance already
+	// is the leader. Long running work should be started in a goroutine.
+	OnElected(fn func(ctx context.Context))
+
+	// OnRevoked registers a function that is called when this instance
+	// stops being the leader, including when the application stops.
+	OnRevoked(fn func())
+}
+
+// Status is the leadership status of the instance, as included in the
+// health report.
+type Status struct {
+	Name     string     `json:"name"`
+	Identity string     `json:"identity"`
+	Backend  string     `json:"backend"`
+	Leader   bool       `json:"leader"`
+	Since    *time.Time `json:"since,omitempty"`
+}
+
+// Elector campaigns for leadership using a Lock, trying to acquire or renew
+// it every retry period.
+type Elector struct {
+	logger     *zap.Logger
+	lock       Lock
+	config     Config
+	attributes metric.MeasurementOption
+	elections  metric.Int64Counter
+
+	leader atomic.Bool
+
+	mutex     sync.Mutex
+	elected   []func(ctx context.Context)
+	revoked   []func()
+	since     time.Time
+	lastRenew time.Time
+	failing   bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+var _ Leadership = (*Elector)(nil)
+
+// NewElector creates an elector using lock. The name, identity and backend
+// of config must have been resolved.
+func NewElector(logger *zap.Logger, meter metric.Meter, lock Lock, config Config) (*Elector, error) {
+	e := &Elector{
+		logger:     logger.With(zap.String("election", config.Name), zap.String("identity", config.Identity)),
+		lock:       lock,
+		config:     config,
+		attributes: metric.WithAttributes(attribute.String("election", config.Name)),
+	}
+
+	elections, err := meter.Int64Counter(
+		"sprout.leader.elections",
+		metric.WithDescription("Number of times this instance has been elected as the leader."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.elections = elections
+
+	_, err = meter.Int64ObservableGauge(
+		"sprout.leader.status",
+		metric.WithDescription("If this instance is the leader, 1 if it is and 0 otherwise."),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			var value int64
+			if e.IsLeader() {
+				value = 1
+			}
+			o.Observe(value, e.attributes)
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *Elector) OnElected(fn func(ctx context.Context)) {
+	e.mutex.Lock()
+	e.elected = append(e.elected, fn)
+	ctx := e.ctx
+	e.mutex.Unlock()
+
+	if ctx != nil {
+		fn(ctx)
+	}
+}
+
+func (e *Elector) OnRevoked(fn func()) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.revoked = append(e.revoked, fn)
+}
+
+// Status returns the current leadership status.
+func (e *Elector) Status() Status {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	status := Status{
+		Name:     e.config.Name,
+		Identity: e.config.Identity,
+		Backend:  e.config.Backend,
+		Leader:   e.IsLeader(),
+	}
+
+	if status.Leader {
+		since := e.since
+		status.Since = &since
+	}
+	return status
+}
+
+// Start starts campaigning for leadership.
+func (e *Elector) Start() {
+	stop := make(chan struct{})
+	e.stop = stop
+
+	e.logger.Info("Starting leader election", zap.String("backend", e.config.Backend))
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.config.RetryPeriod)
+		defer ticker.Stop()
+
+		for {
+			e.campaign()
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning, giving up leadership if this instance is the
+// leader.
+func (e *Elector) Stop(ctx context.Context) error {
+	if e.stop == nil {
+		return nil
+	}
+
+	close(e.stop)
+	e.wg.Wait()
+
+	if e.IsLeader() {
+		e.revoke("stopping")
+	}
+
+	return e.lock.Release(ctx)
+}
+
+// campaign tries to acquire or renew the lock once.
+func (e *Elector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.RetryPeriod)
+	defer cancel()
+
+	acquired, err := e.lock.Acquire(ctx)
+	now := time.Now()
+
+	e.mutex.Lock()
+	wasFailing := e.failing
+	e.failing = err != nil
+	if acquired {
+		e.lastRenew = now
+	}
+	lastRenew := e.lastRenew
+	e.mutex.Unlock()
+
+	switch {
+	case err != nil:
+		if !wasFailing {
+			e.logger.Warn("Could not acquire leadership", zap.Error(err))
+		}
+
+		if e.IsLeader() && now.Sub(lastRenew) > e.config.RenewDeadline {
+			e.revoke("could not renew leadership")
+
+			// Release in case the backend still considers this instance the
+			// leader, so that another instance can take over
+			if err := e.lock.Release(ctx); err != nil {
+				e.logger.Debug("Could not release lock", zap.Error(err))
+			}
+		}
+	case acquired:
+		if wasFailing {
+			e.logger.Info("Leader election recovered")
+		}
+
+		if !e.IsLeader() {
+			e.elect(now)
+		}
+	default:
+		if wasFailing {
+			e.logger.Info("Leader election recovered")
+		}
+
+		if e.IsLeader() {
+			e.revoke("another instance is the leader")
+		}
+	}
+}
+
+func (e *Elector) elect(now time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e.mutex.Lock()
+	e.since = now
+	e.ctx = ctx
+	e.cancel = cancel
+	callbacks := slices.Clone(e.elected)
+	e.leader.Store(true)
+	e.mutex.Unlock()
+
+	e.logger.Info("Elected as leader")
+	e.elections.Add(ctx, 1, e.attributes)
+
+	for _, fn := range callbacks {
+		fn(ctx)
+	}
+}
+
+func (e *Elector) revoke(reason string) {
+	e.mutex.Lock()
+	cancel := e.cancel
+	since := e.since
+	e.ctx = nil
+	e.cancel = nil
+	e.since = time.Time{}
+	callbacks := slices.Clone(e.revoked)
+	e.leader.Store(false)
+	e.mutex.Unlock()
+
+	cancel()
+	e.logger.Info("No longer leader", zap.String("reason", reason), zap.Duration("duration", time.Since(since)))
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
@@ -0,0 +1,229 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/leader"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeLock is a lock controlled by the test.
+type fakeLock struct {
+	mutex    sync.Mutex
+	held     bool
+	err      error
+	released bool
+}
+
+func (l *fakeLock) set(held bool, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.held = held
+	l.err = err
+}
+
+func (l *fakeLock) Acquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.held && l.err == nil, l.err
+}
+
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.released = true
+	return nil
+}
+
+var _ = Describe("Elector", func() {
+	var reader *sdkmetric.ManualReader
+
+	BeforeEach(func() {
+		t := GinkgoT()
+		t.Setenv("LEADER_RETRY_PERIOD", "20ms")
+		t.Setenv("LEADER_RENEW_DEADLINE", "100ms")
+		t.Setenv("LEADER_LEASE_DURATION", "1s")
+	})
+
+	newApp := func(options ...fx.Option) *fxtest.App {
+		reader = sdkmetric.NewManualReader()
+		meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		return fxtest.New(
+			GinkgoT(),
+			fx.Supply(internal.ServiceInfo{Name: "test"}),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(meterProvider, fx.As(new(metric.MeterProvider)))),
+			health.Module,
+			leader.Module,
+			fx.Options(options...),
+		)
+	}
+
+	withLock := func(lock leader.Lock) fx.Option {
+		return fx.Supply(fx.Annotate(lock, fx.As(new(leader.Lock)), fx.ResultTags(`name:"sprout:leader:lock"`)))
+	}
+
+	It("requires a backend", func() {
+		app := fx.New(
+			fx.NopLogger,
+			fx.Supply(internal.ServiceInfo{Name: "test"}),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(fx.Annotate(sdkmetric.NewMeterProvider(), fx.As(new(metric.MeterProvider)))),
+			health.Module,
+			leader.Module,
+			fx.Invoke(func(leader.Leadership) {}),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("LEADER_BACKEND")))
+	})
+
+	It("calls callbacks when elected and revoked", func() {
+		lock := &fakeLock{}
+
+		var (
+			elected    atomic.Int32
+			revoked    atomic.Int32
+			leaderCtx  atomic.Pointer[context.Context]
+			leadership leader.Leadership
+		)
+		app := newApp(
+			withLock(lock),
+			fx.Invoke(func(l leader.Leadership) {
+				leadership = l
+				l.OnElected(func(ctx context.Context) {
+					elected.Add(1)
+					leaderCtx.Store(&ctx)
+				})
+				l.OnRevoked(func() {
+					revoked.Add(1)
+				})
+			}),
+		)
+		app.RequireStart()
+
+		Consistently(leadership.IsLeader, "100ms").Should(BeFalse())
+		Expect(elected.Load()).To(BeZero())
+
+		lock.set(true, nil)
+		Eventually(leadership.IsLeader).Should(BeTrue())
+		Expect(elected.Load()).To(Equal(int32(1)))
+		ctx := *leaderCtx.Load()
+		Expect(ctx.Err()).ToNot(HaveOccurred())
+
+		// Callbacks added while leader are called immediately
+		var late atomic.Bool
+		leadership.OnElected(func(ctx context.Context) {
+			late.Store(true)
+		})
+		Expect(late.Load()).To(BeTrue())
+
+		lock.set(false, nil)
+		Eventually(leadership.IsLeader).Should(BeFalse())
+		Expect(revoked.Load()).To(Equal(int32(1)))
+		Expect(ctx.Err()).To(HaveOccurred())
+
+		lock.set(true, nil)
+		Eventually(leadership.IsLeader).Should(BeTrue())
+		Expect(elected.Load()).To(Equal(int32(2)))
+
+		app.RequireStop()
+		Expect(leadership.IsLeader()).To(BeFalse())
+		Expect(revoked.Load()).To(Equal(int32(2)))
+		Expect(lock.released).To(BeTrue())
+	})
+
+	It("gives up leadership if it can not be renewed", func() {
+		lock := &fakeLock{held: true}
+
+		var leadership leader.Leadership
+		app := newApp(withLock(lock), fx.Populate(&leadership))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(leadership.IsLeader).Should(BeTrue())
+
+		// Failures within the renew deadline are tolerated
+		lock.set(true, errors.New("unavailable"))
+		Consistently(leadership.IsLeader, "50ms").Should(BeTrue())
+		Eventually(leadership.IsLeader).Should(BeFalse())
+
+		lock.set(true, nil)
+		Eventually(leadership.IsLeader).Should(BeTrue())
+	})
+
+	It("elects one instance using a file", func() {
+		t := GinkgoT()
+		t.Setenv("LEADER_BACKEND", "file")
+		t.Setenv("LEADER_FILE", filepath.Join(t.TempDir(), "test.lock"))
+
+		var first, second leader.Leadership
+		firstApp := newApp(fx.Populate(&first))
+		firstApp.RequireStart()
+		Eventually(first.IsLeader).Should(BeTrue())
+
+		// Only one health server can listen on the port
+		t.Setenv("HEALTH_SERVER_ENABLED", "false")
+		secondApp := newApp(fx.Populate(&second))
+		secondApp.RequireStart()
+		defer secondApp.RequireStop()
+
+		Consistently(second.IsLeader, "100ms").Should(BeFalse())
+
+		firstApp.RequireStop()
+		Eventually(second.IsLeader).Should(BeTrue())
+	})
+
+	It("reports leadership in health reports and metrics", func() {
+		lock := &fakeLock{held: true}
+
+		var leadership leader.Leadership
+		app := newApp(withLock(lock), fx.Populate(&leadership))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(leadership.IsLeader).Should(BeTrue())
+
+		res, err := http.Get("http://localhost:8099/readyz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body).To(ContainSubstring(`"leader":{"name":"test","identity":`))
+		Expect(body).To(ContainSubstring(`"backend":"custom","leader":true,"since":`))
+
+		var data metricdata.ResourceMetrics
+		Expect(reader.Collect(context.Background(), &data)).To(Succeed())
+		values := map[string]int64{}
+		for _, m := range data.ScopeMetrics[0].Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			case metricdata.Gauge[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			}
+		}
+		Expect(values).To(Equal(map[string]int64{
+			"sprout.leader.elections": 1,
+			"sprout.leader.status":    1,
+		}))
+	})
+})
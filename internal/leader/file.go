@@ -0,0 +1,76 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// fileLock is a lock backed by an exclusive lock on a file, for running
+// several instances on the same machine. The lock is released by the
+// operating system if the process exits.
+type fileLock struct {
+	path     string
+	identity string
+
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileLock creates a lock backed by the file at path, which is created if
+// it does not exist. The identity of the leader is written to the file.
+func NewFileLock(path string, identity string) Lock {
+	return &fileLock{
+		path:     path,
+		identity: identity,
+	}
+}
+
+func (l *fileLock) Acquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file != nil {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		_ = file.Close()
+		return false, nil
+	} else if err != nil {
+		_ = file.Close()
+		return false, err
+	}
+
+	// The identity is informational, failing to write it does not matter
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(l.identity+"\n"), 0)
+	}
+
+	l.file = file
+	return true, nil
+}
+
+func (l *fileLock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	// Closing the file releases the lock
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,24 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package leader
+
+import (
+	"context"
+	"errors"
+)
+
+// NewFileLock is not supported on this platform, the returned lock fails to
+// acquire.
+func NewFileLock(path string, identity string) Lock {
+	return unsupportedLock{}
+}
+
+type unsupportedLock struct{}
+
+func (unsupportedLock) Acquire(ctx context.Context) (bool, error) {
+	return false, errors.New("file locks are not supported on this platform")
+}
+
+func (unsupportedLock) Release(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,34 @@
+package leader_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/aholstenson/sprout-go/internal/leader"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File lock", func() {
+	ctx := context.Background()
+
+	It("is only acquired by one lock at a time", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "test.lock")
+		first := leader.NewFileLock(path, "first")
+		second := leader.NewFileLock(path, "second")
+
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(second.Acquire(ctx)).To(BeFalse())
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("first\n"))
+
+		Expect(first.Release(ctx)).To(Succeed())
+		Expect(second.Acquire(ctx)).To(BeTrue())
+		Expect(first.Acquire(ctx)).To(BeFalse())
+		Expect(second.Release(ctx)).To(Succeed())
+	})
+})
@@ -0,0 +1,312 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const microTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("conflict")
+)
+
+// KubernetesConfig configures the lock backed by a Kubernetes Lease.
+type KubernetesConfig struct {
+	// Namespace is the namespace of the Lease, defaults to the namespace of
+	// the service account
+	Namespace string `env:"NAMESPACE"`
+	// ServiceAccountDir is the directory containing the token, CA and
+	// namespace of the service account
+	ServiceAccountDir string `env:"SERVICE_ACCOUNT_DIR" envDefault:"/var/run/secrets/kubernetes.io/serviceaccount"`
+}
+
+// lease is a coordination.k8s.io/v1 Lease. The metadata is kept as is so
+// that updates do not remove fields set by others.
+type lease struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   map[string]any `json:"metadata"`
+	Spec       leaseSpec      `json:"spec"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string     `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int        `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *microTime `json:"acquireTime,omitempty"`
+	RenewTime            *microTime `json:"renewTime,omitempty"`
+	LeaseTransitions     int        `json:"leaseTransitions,omitempty"`
+}
+
+type microTime struct {
+	time.Time
+}
+
+func (t microTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(microTimeLayout))
+}
+
+func (t *microTime) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed
+	return nil
+}
+
+// kubernetesLock is a lock backed by a Lease in Kubernetes, using the API
+// server within the cluster and the service account of the pod.
+type kubernetesLock struct {
+	client        *http.Client
+	leases        string
+	tokenFile     string
+	namespace     string
+	name          string
+	identity      string
+	leaseDuration time.Duration
+
+	mutex sync.Mutex
+	// observed is the last seen spec of the Lease and observedAt is the
+	// local time it was seen, used instead of the renew time of the Lease so
+	// that clocks do not need to be in sync
+	observed   leaseSpec
+	observedAt time.Time
+}
+
+// NewKubernetesLock creates a lock backed by the Lease with the given name.
+// The service account of the pod needs permission to get, create and update
+// Leases in the namespace.
+func NewKubernetesLock(config KubernetesConfig, name string, identity string, leaseDuration time.Duration) (Lock, error) {
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	port := os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("kubernetes leader election requires running in a cluster, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+
+	namespace := config.Namespace
+	if namespace == "" {
+		data, err := os.ReadFile(filepath.Join(config.ServiceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("could not determine namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	ca, err := os.ReadFile(filepath.Join(config.ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA of service account: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates found in CA of service account")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	leases := (&url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(host, port),
+		Path:   "/apis/coordination.k8s.io/v1/namespaces/" + url.PathEscape(namespace) + "/leases",
+	}).String()
+
+	return &kubernetesLock{
+		client: &http.Client{
+			Transport: transport,
+		},
+		leases:        leases,
+		tokenFile:     filepath.Join(config.ServiceAccountDir, "token"),
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+	}, nil
+}
+
+func (l *kubernetesLock) Acquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	current, err := l.get(ctx)
+	if errors.Is(err, errNotFound) {
+		created := &lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata: map[string]any{
+				"name":      l.name,
+				"namespace": l.namespace,
+			},
+			Spec: leaseSpec{
+				HolderIdentity:       l.identity,
+				LeaseDurationSeconds: l.leaseDurationSeconds(),
+				AcquireTime:          &microTime{now},
+				RenewTime:            &microTime{now},
+			},
+		}
+
+		err = l.do(ctx, http.MethodPost, l.leases, created, nil)
+		if errors.Is(err, errConflict) {
+			// Another instance created it first
+			return false, nil
+		}
+		return err == nil, err
+	} else if err != nil {
+		return false, err
+	}
+
+	spec := current.Spec
+	if !l.sameAsObserved(spec) {
+		l.observed = spec
+		l.observedAt = now
+	}
+
+	held := spec.HolderIdentity == l.identity
+	expired := l.observedAt.Add(time.Duration(spec.LeaseDurationSeconds) * time.Second).Before(now)
+	if !held && spec.HolderIdentity != "" && !expired {
+		return false, nil
+	}
+
+	if !held {
+		spec.AcquireTime = &microTime{now}
+		spec.LeaseTransitions++
+	}
+	spec.HolderIdentity = l.identity
+	spec.LeaseDurationSeconds = l.leaseDurationSeconds()
+	spec.RenewTime = &microTime{now}
+	current.Spec = spec
+
+	err = l.do(ctx, http.MethodPut, l.leaseURL(), current, nil)
+	if errors.Is(err, errConflict) {
+		// Updated by another instance since it was read
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (l *kubernetesLock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	current, err := l.get(ctx)
+	if errors.Is(err, errNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if current.Spec.HolderIdentity != l.identity {
+		return nil
+	}
+
+	current.Spec.HolderIdentity = ""
+	current.Spec.LeaseDurationSeconds = 1
+	err = l.do(ctx, http.MethodPut, l.leaseURL(), current, nil)
+	if errors.Is(err, errConflict) {
+		return nil
+	}
+	return err
+}
+
+func (l *kubernetesLock) sameAsObserved(spec leaseSpec) bool {
+	if spec.HolderIdentity != l.observed.HolderIdentity {
+		return false
+	}
+
+	if spec.RenewTime == nil || l.observed.RenewTime == nil {
+		return spec.RenewTime == l.observed.RenewTime
+	}
+
+	return spec.RenewTime.Equal(l.observed.RenewTime.Time)
+}
+
+func (l *kubernetesLock) leaseDurationSeconds() int {
+	return max(int(l.leaseDuration/time.Second), 1)
+}
+
+func (l *kubernetesLock) leaseURL() string {
+	return l.leases + "/" + url.PathEscape(l.name)
+}
+
+func (l *kubernetesLock) get(ctx context.Context) (*lease, error) {
+	var result lease
+	if err := l.do(ctx, http.MethodGet, l.leaseURL(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (l *kubernetesLock) do(ctx context.Context, method string, url string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+
+	// The token is rotated, so it is read for every request
+	token, err := os.ReadFile(l.tokenFile)
+	if err != nil {
+		return fmt.Errorf("could not read token of service account: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case res.StatusCode == http.StatusConflict:
+		return errConflict
+	case res.StatusCode >= 300:
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("kubernetes API returned %s: %s", res.Status, strings.TrimSpace(string(message)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
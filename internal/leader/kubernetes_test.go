@@ -0,0 +1,173 @@
+package leader_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/leader"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// leaseServer is a minimal Kubernetes API server storing Leases, rejecting
+// updates with an outdated resourceVersion.
+type leaseServer struct {
+	mutex   sync.Mutex
+	leases  map[string]map[string]any
+	version int
+}
+
+func (s *leaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	const prefix = "/apis/coordination.k8s.io/v1/namespaces/test/leases"
+	switch {
+	case r.Method == http.MethodGet && len(r.URL.Path) > len(prefix):
+		lease, ok := s.leases[r.URL.Path[len(prefix)+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(lease)
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		lease := s.decode(r)
+		name := lease["metadata"].(map[string]any)["name"].(string)
+		if _, exists := s.leases[name]; exists {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.store(name, lease)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		name := r.URL.Path[len(prefix)+1:]
+		lease := s.decode(r)
+		current, ok := s.leases[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if current["metadata"].(map[string]any)["resourceVersion"] != lease["metadata"].(map[string]any)["resourceVersion"] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.store(name, lease)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *leaseServer) decode(r *http.Request) map[string]any {
+	var lease map[string]any
+	Expect(json.NewDecoder(r.Body).Decode(&lease)).To(Succeed())
+	return lease
+}
+
+func (s *leaseServer) store(name string, lease map[string]any) {
+	s.version++
+	lease["metadata"].(map[string]any)["resourceVersion"] = strconv.Itoa(s.version)
+	s.leases[name] = lease
+}
+
+func (s *leaseServer) spec(name string) map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.leases[name]["spec"].(map[string]any)
+}
+
+var _ = Describe("Kubernetes lock", func() {
+	var (
+		server *leaseServer
+		config leader.KubernetesConfig
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = &leaseServer{leases: map[string]map[string]any{}}
+		httpServer := httptest.NewTLSServer(server)
+		DeferCleanup(httpServer.Close)
+
+		dir := GinkgoT().TempDir()
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: httpServer.Certificate().Raw})
+		Expect(os.WriteFile(filepath.Join(dir, "ca.crt"), ca, 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "token"), []byte("test-token\n"), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "namespace"), []byte("test"), 0o600)).To(Succeed())
+
+		host, port, err := net.SplitHostPort(httpServer.Listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", host)
+		GinkgoT().Setenv("KUBERNETES_SERVICE_PORT", port)
+
+		config = leader.KubernetesConfig{
+			ServiceAccountDir: dir,
+		}
+	})
+
+	newLock := func(identity string) leader.Lock {
+		lock, err := leader.NewKubernetesLock(config, "test", identity, time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		return lock
+	}
+
+	It("requires running in a cluster", func() {
+		GinkgoT().Setenv("KUBERNETES_SERVICE_HOST", "")
+
+		_, err := leader.NewKubernetesLock(config, "test", "first", time.Second)
+		Expect(err).To(MatchError(ContainSubstring("KUBERNETES_SERVICE_HOST")))
+	})
+
+	It("creates and renews the lease", func() {
+		first := newLock("first")
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(server.spec("test")).To(HaveKeyWithValue("holderIdentity", "first"))
+		Expect(server.spec("test")).To(HaveKeyWithValue("leaseDurationSeconds", BeEquivalentTo(1)))
+
+		renewTime := server.spec("test")["renewTime"]
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(server.spec("test")["renewTime"]).ToNot(Equal(renewTime))
+	})
+
+	It("is not acquired while held by another instance", func() {
+		first := newLock("first")
+		second := newLock("second")
+
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(second.Acquire(ctx)).To(BeFalse())
+
+		Expect(first.Release(ctx)).To(Succeed())
+		Expect(server.spec("test")).ToNot(HaveKey("holderIdentity"))
+
+		Expect(second.Acquire(ctx)).To(BeTrue())
+		Expect(server.spec("test")).To(HaveKeyWithValue("holderIdentity", "second"))
+		Expect(server.spec("test")).To(HaveKeyWithValue("leaseTransitions", BeEquivalentTo(1)))
+	})
+
+	It("is acquired when the lease expires", func() {
+		first := newLock("first")
+		second := newLock("second")
+
+		Expect(first.Acquire(ctx)).To(BeTrue())
+		Expect(second.Acquire(ctx)).To(BeFalse())
+
+		Eventually(func() (bool, error) {
+			return second.Acquire(ctx)
+		}, "3s", "100ms").Should(BeTrue())
+		Expect(first.Acquire(ctx)).To(BeFalse())
+	})
+})
@@ -0,0 +1,16 @@
+package leader_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLeader(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8099")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Leader Suite")
+}
@@ -0,0 +1,17 @@
+package leader
+
+import (
+	"context"
+)
+
+// Lock is a backend used for leader election. The elector calls Acquire
+// periodically, so implementations only need to try once.
+type Lock interface {
+	// Acquire tries to acquire the lock, or renew it if it is already held,
+	// and returns if it is held by this instance.
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release releases the lock if it is held, allowing another instance to
+	// become the leader without waiting for the lock to expire.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,169 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	BackendKubernetes = "kubernetes"
+	BackendPostgres   = "postgres"
+	BackendFile       = "file"
+	// BackendCustom is reported as the backend when a Lock is provided by
+	// the application
+	BackendCustom = "custom"
+)
+
+type Config struct {
+	// Backend is the lock used for the election, one of kubernetes, postgres
+	// and file
+	Backend string `env:"BACKEND"`
+	// Name identifies the election, defaults to the name of the service
+	Name string `env:"NAME"`
+	// Identity identifies this instance, defaults to the hostname
+	Identity string `env:"IDENTITY"`
+
+	// LeaseDuration is how long other instances wait before taking over
+	// leadership if the leader stops renewing it
+	LeaseDuration time.Duration `env:"LEASE_DURATION" envDefault:"15s"`
+	// RenewDeadline is how long the leader tries to renew leadership before
+	// giving it up, must be less than LeaseDuration
+	RenewDeadline time.Duration `env:"RENEW_DEADLINE" envDefault:"10s"`
+	// RetryPeriod is how often the lock is acquired or renewed
+	RetryPeriod time.Duration `env:"RETRY_PERIOD" envDefault:"2s"`
+
+	// File is the lock file used by the file backend, defaults to a file
+	// named after the election in the temporary directory
+	File string `env:"FILE"`
+	// Kubernetes configures the kubernetes backend
+	Kubernetes KubernetesConfig `envPrefix:"KUBERNETES_"`
+}
+
+func (c Config) validate() error {
+	if c.RetryPeriod <= 0 {
+		return errors.New("LEADER_RETRY_PERIOD must be positive")
+	}
+
+	if c.RenewDeadline <= c.RetryPeriod || c.RenewDeadline >= c.LeaseDuration {
+		return errors.New("LEADER_RENEW_DEADLINE must be greater than LEADER_RETRY_PERIOD and less than LEADER_LEASE_DURATION")
+	}
+
+	return nil
+}
+
+// Module provides Leadership, electing a leader via the lock named
+// sprout:leader:lock if available and otherwise via the backend configured
+// in LEADER_BACKEND. The election only runs if Leadership is used.
+var Module = fx.Module(
+	"sprout:leader",
+	fx.Provide(config.Config("LEADER", Config{}), fx.Private),
+	fx.Provide(logging.Logger("leader"), fx.Private),
+	fx.Provide(fx.Annotate(provide, fx.As(new(Leadership)))),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle     fx.Lifecycle
+	Logger        *zap.Logger
+	Config        Config
+	Checks        health.Checks
+	MeterProvider metric.MeterProvider
+	ServiceName   string `name:"service:name"`
+	Command       string `name:"service:command" optional:"true"`
+
+	Lock Lock    `name:"sprout:leader:lock" optional:"true"`
+	DB   *sql.DB `optional:"true"`
+}
+
+func provide(p params) (*Elector, error) {
+	c := p.Config
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.Name == "" {
+		c.Name = p.ServiceName
+	}
+
+	if c.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine identity for leader election: %w", err)
+		}
+		c.Identity = hostname
+	}
+
+	lock := p.Lock
+	if lock != nil {
+		c.Backend = BackendCustom
+	} else {
+		var err error
+		lock, err = createLock(c, p.DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	elector, err := NewElector(p.Logger, p.MeterProvider.Meter("sprout/leader"), lock, c)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Checks.AddInfo("leader", func() any {
+		return elector.Status()
+	})
+
+	if p.Command != "" {
+		p.Logger.Info("Not running leader election while running a command", zap.String("command", p.Command))
+		return elector, nil
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			elector.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// Stopping runs the OnRevoked callbacks of the application
+			defer crash.Recover(ctx, "leader OnStop hook")
+			return elector.Stop(ctx)
+		},
+	})
+	return elector, nil
+}
+
+func createLock(c Config, db *sql.DB) (Lock, error) {
+	switch c.Backend {
+	case BackendKubernetes:
+		return NewKubernetesLock(c.Kubernetes, c.Name, c.Identity, c.LeaseDuration)
+	case BackendPostgres:
+		if db == nil {
+			return nil, errors.New("postgres leader election requires a database, see sprout.SQL")
+		}
+		return NewPostgresLock(db, c.Name), nil
+	case BackendFile:
+		file := c.File
+		if file == "" {
+			file = filepath.Join(os.TempDir(), c.Name+".lock")
+		}
+		return NewFileLock(file, c.Identity), nil
+	case "":
+		return nil, errors.New("leader election requires LEADER_BACKEND to be set")
+	default:
+		return nil, fmt.Errorf("unsupported leader election backend %q", c.Backend)
+	}
+}
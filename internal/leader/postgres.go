@@ -0,0 +1,96 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+// postgresLock is a lock backed by a session level advisory lock in
+// PostgreSQL. The lock is held by a dedicated connection, and is released
+// by the server if the connection is lost.
+type postgresLock struct {
+	db  *sql.DB
+	key int64
+
+	mutex sync.Mutex
+	conn  *sql.Conn
+}
+
+// NewPostgresLock creates a lock backed by an advisory lock with a key
+// derived from name.
+func NewPostgresLock(db *sql.DB, name string) Lock {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+
+	return &postgresLock{
+		db:  db,
+		key: int64(hash.Sum64()),
+	}
+}
+
+func (l *postgresLock) Acquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conn != nil {
+		// The lock is held as long as the connection is alive. The session
+		// may still hold the lock if pinging failed, so the connection is
+		// discarded instead of being returned to the pool
+		if err := l.conn.PingContext(ctx); err != nil {
+			discard(l.conn)
+			_ = l.conn.Close()
+			l.conn = nil
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+		return false, err
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+func (l *postgresLock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+
+	// Discard the connection if unlocking failed so the lock is not kept
+	// by a connection returned to the pool
+	if err != nil {
+		discard(l.conn)
+	}
+
+	closeErr := l.conn.Close()
+	l.conn = nil
+	return errors.Join(err, closeErr)
+}
+
+// discard marks the connection as bad, so that closing it closes the
+// session instead of returning it to the pool.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(driverConn any) error {
+		return driver.ErrBadConn
+	})
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a context carrying fields that are added to loggers
+// created via FromContext, in addition to fields already in the context.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := Fields(ctx)
+	return context.WithValue(ctx, fieldsKey{}, append(slices.Clip(existing), fields...))
+}
+
+// Fields returns the fields added to the context via WithFields.
+func Fields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// FromContext returns a logger with the fields of the context and its
+// baggage, which is added as an object named baggage.
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+
+	members := baggage.FromContext(ctx).Members()
+	if len(members) > 0 {
+		values := make([]zap.Field, 0, len(members))
+		for _, member := range members {
+			values = append(values, zap.String(member.Key(), member.Value()))
+		}
+		fields = append(slices.Clip(fields), zap.Dict("baggage", values...))
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
@@ -0,0 +1,54 @@
+package logging_test
+
+import (
+	"context"
+
+	"github.com/aholstenson/sprout-go/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("Context", func() {
+	It("returns the logger if the context has no fields", func() {
+		logger := zap.NewNop()
+		Expect(logging.FromContext(context.Background(), logger)).To(BeIdenticalTo(logger))
+	})
+
+	It("adds fields of the context", func() {
+		core, logs := observer.New(zapcore.InfoLevel)
+
+		ctx := logging.WithFields(context.Background(), zap.String("orderID", "1"))
+		first := logging.WithFields(ctx, zap.String("step", "first"))
+		second := logging.WithFields(ctx, zap.String("step", "second"))
+
+		logging.FromContext(first, zap.New(core)).Info("First")
+		logging.FromContext(second, zap.New(core)).Info("Second")
+
+		entries := logs.All()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].ContextMap()).To(Equal(map[string]any{"orderID": "1", "step": "first"}))
+		Expect(entries[1].ContextMap()).To(Equal(map[string]any{"orderID": "1", "step": "second"}))
+	})
+
+	It("adds baggage of the context", func() {
+		core, logs := observer.New(zapcore.InfoLevel)
+
+		member, err := baggage.NewMemberRaw("tenant", "acme")
+		Expect(err).ToNot(HaveOccurred())
+		b, err := baggage.New(member)
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx := baggage.ContextWithBaggage(context.Background(), b)
+		ctx = logging.WithFields(ctx, zap.String("orderID", "1"))
+		logging.FromContext(ctx, zap.New(core)).Info("Test")
+
+		Expect(logs.All()[0].ContextMap()).To(Equal(map[string]any{
+			"orderID": "1",
+			"baggage": map[string]any{"tenant": "acme"},
+		}))
+	})
+})
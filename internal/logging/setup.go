@@ -6,6 +6,7 @@ import (
 
 	"github.com/aholstenson/sprout-go/internal"
 	"github.com/aholstenson/sprout-go/internal/otel"
+	"github.com/aholstenson/sprout-go/internal/shutdown"
 	"github.com/caarlos0/env/v11"
 	prettyconsole "github.com/thessem/zap-prettyconsole"
 	"go.opentelemetry.io/contrib/bridges/otelzap"
@@ -22,9 +23,17 @@ type logConfig struct {
 	} `env:"LOG_SAMPLING"`
 }
 
-// CreateRootLogger creates the root logger of the application.
-func CreateRootLogger(serviceInfo internal.ServiceInfo) (*zap.Logger, error) {
-	opts := []zap.Option{zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel)}
+// CreateRootLogger creates the root logger of the application. Fatal logs
+// flush the components registered with the coordinator before exiting.
+func CreateRootLogger(
+	serviceInfo internal.ServiceInfo,
+	coordinator *shutdown.Coordinator,
+) (*zap.Logger, error) {
+	opts := []zap.Option{
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.WithFatalHook(coordinator.FatalHook()),
+	}
 	var cores []zapcore.Core
 
 	config, err := env.ParseAs[logConfig]()
@@ -53,7 +62,7 @@ func CreateRootLogger(serviceInfo internal.ServiceInfo) (*zap.Logger, error) {
 	}
 
 	// Connect to OpenTelemetry
-	provider, ok, err := otel.InitLogging(serviceInfo)
+	provider, ok, err := otel.InitLogging(serviceInfo, coordinator)
 	if err != nil {
 		return nil, err
 	} else if ok {
@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// dialect contains the database specific parts of running migrations.
+type dialect struct {
+	// placeholder returns the placeholder of the n:th argument of a query,
+	// starting at 1
+	placeholder func(n int) string
+	// lock acquires a lock held until unlock is called, ensuring that only
+	// one replica migrates at a time
+	lock func(ctx context.Context, conn *sql.Conn, name string) (unlock func(ctx context.Context) error, err error)
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
+		lock: func(ctx context.Context, conn *sql.Conn, name string) (func(ctx context.Context) error, error) {
+			key := lockKey(name)
+			if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context) error {
+				_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+				return err
+			}, nil
+		},
+	},
+	"mysql": {
+		placeholder: func(n int) string {
+			return "?"
+		},
+		lock: func(ctx context.Context, conn *sql.Conn, name string) (func(ctx context.Context) error, error) {
+			var acquired sql.NullInt64
+			if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", name).Scan(&acquired); err != nil {
+				return nil, err
+			}
+
+			if acquired.Int64 != 1 {
+				return nil, fmt.Errorf("unable to acquire lock %s", name)
+			}
+
+			return func(ctx context.Context) error {
+				_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+				return err
+			}, nil
+		},
+	},
+	"sqlite": {
+		placeholder: func(n int) string {
+			return "?"
+		},
+		lock: func(ctx context.Context, conn *sql.Conn, name string) (func(ctx context.Context) error, error) {
+			// Databases are local files, writes are serialized by SQLite
+			return func(ctx context.Context) error {
+				return nil
+			}, nil
+		},
+	},
+}
+
+// driverDialects maps names of common drivers to their dialect.
+var driverDialects = map[string]string{
+	"postgres":         "postgres",
+	"pgx":              "postgres",
+	"pgx/v5":           "postgres",
+	"cloudsqlpostgres": "postgres",
+	"mysql":            "mysql",
+	"sqlite":           "sqlite",
+	"sqlite3":          "sqlite",
+}
+
+// findDialect returns the named dialect, or the dialect of the driver if no
+// name is given.
+func findDialect(name string, driverName string) (dialect, error) {
+	if name == "" {
+		var ok bool
+		name, ok = driverDialects[driverName]
+		if !ok {
+			return dialect{}, fmt.Errorf("unable to determine dialect of driver %s, set SQL_MIGRATE_DIALECT", driverName)
+		}
+	}
+
+	d, ok := dialects[name]
+	if !ok {
+		return dialect{}, fmt.Errorf("unsupported dialect %s", name)
+	}
+	return d, nil
+}
+
+// lockKey converts the name of a lock into a key for advisory locks.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,16 @@
+package migrate_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMigrate(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8097")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migrate Suite")
+}
@@ -0,0 +1,270 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing/fstest"
+
+	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/migrate"
+	"github.com/aholstenson/sprout-go/internal/sqldb"
+	"github.com/aholstenson/sprout-go/internal/worker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	_ "modernc.org/sqlite"
+)
+
+var _ = Describe("Migrations", func() {
+	var recorder *tracetest.SpanRecorder
+	var logs *observer.ObservedLogs
+	var dsn string
+	var hooks *hookRecorder
+	var command string
+
+	BeforeEach(func() {
+		hooks = &hookRecorder{}
+		command = ""
+		recorder = tracetest.NewSpanRecorder()
+		dsn = filepath.Join(GinkgoT().TempDir(), "test.db")
+	})
+
+	newApp := func(fsys fs.FS, options ...fx.Option) *fx.App {
+		GinkgoT().Setenv("SQL_DSN", dsn)
+
+		var core zapcore.Core
+		core, logs = observer.New(zap.InfoLevel)
+
+		return fx.New(
+			fx.WithLogger(func() fxevent.Logger { return hooks }),
+			logging.Module(zap.New(core)),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}),
+			fx.Supply(internal.ServiceInfo{
+				Name:    "test",
+				Version: "v1.0.0",
+				Command: command,
+			}),
+			sqldb.Module("sqlite"),
+			migrate.Module(fsys),
+			migrate.Startup,
+			worker.Module,
+			fx.Options(options...),
+		)
+	}
+
+	run := func(fsys fs.FS, options ...fx.Option) error {
+		app := newApp(fsys, options...)
+		if err := app.Start(context.Background()); err != nil {
+			return err
+		}
+		return app.Stop(context.Background())
+	}
+
+	open := func() *sql.DB {
+		db, err := sql.Open("sqlite", dsn)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(db.Close)
+		return db
+	}
+
+	appliedVersions := func() []int {
+		rows, err := open().Query("SELECT version FROM sprout_migrations ORDER BY version")
+		Expect(err).ToNot(HaveOccurred())
+		defer rows.Close()
+
+		var versions []int
+		for rows.Next() {
+			var version int
+			Expect(rows.Scan(&version)).To(Succeed())
+			versions = append(versions, version)
+		}
+		return versions
+	}
+
+	It("applies migrations when starting", func() {
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+		Expect(appliedVersions()).To(Equal([]int{1, 2}))
+
+		_, err := open().Exec("INSERT INTO users (name, email) VALUES ('test', 'test@example.com')")
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(ContainElements("migrate up", "migration up 1_create_users", "migration up 2_add_email"))
+		Expect(logs.FilterMessage("Migration completed").Len()).To(Equal(2))
+	})
+
+	It("does not apply migrations twice", func() {
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+
+		Expect(appliedVersions()).To(Equal([]int{1, 2}))
+		Expect(logs.FilterMessage("Database schema is up to date").Len()).To(Equal(1))
+	})
+
+	It("is ready once migrations have been applied", func() {
+		var status health.Status
+		app := newApp(os.DirFS("testdata/migrations"), fx.Populate(&status))
+		Expect(app.Start(context.Background())).To(Succeed())
+		defer func() { _ = app.Stop(context.Background()) }()
+
+		// Results of the readiness checks are cached for a second
+		Eventually(func() bool {
+			return status.Ready(context.Background())
+		}, "3s").Should(BeTrue())
+	})
+
+	It("fails to start if a migration fails", func() {
+		Expect(run(os.DirFS("testdata/broken"))).To(MatchError(ContainSubstring("migration 2_invalid failed")))
+		Expect(appliedVersions()).To(Equal([]int{1}))
+	})
+
+	It("exits once migrated in migrate only mode", func() {
+		GinkgoT().Setenv("SQL_MIGRATE_ONLY", "true")
+
+		app := newApp(os.DirFS("testdata/migrations"))
+		Expect(app.Start(context.Background())).To(Succeed())
+		defer func() { _ = app.Stop(context.Background()) }()
+
+		Eventually(app.Wait()).Should(Receive(HaveField("ExitCode", 0)))
+		Expect(appliedVersions()).To(Equal([]int{1, 2}))
+	})
+
+	It("applies migrations before workers start", func() {
+		Expect(run(os.DirFS("testdata/migrations"), fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})))).To(Succeed())
+
+		Expect(hooks.names).To(ContainElements(
+			ContainSubstring("internal/migrate."),
+			ContainSubstring("internal/worker."),
+		))
+		migrateIndex := slices.IndexFunc(hooks.names, func(name string) bool {
+			return strings.Contains(name, "internal/migrate.")
+		})
+		workerIndex := slices.IndexFunc(hooks.names, func(name string) bool {
+			return strings.Contains(name, "internal/worker.")
+		})
+		Expect(migrateIndex).To(BeNumerically("<", workerIndex))
+	})
+
+	It("does not start workers in migrate only mode", func() {
+		GinkgoT().Setenv("SQL_MIGRATE_ONLY", "true")
+
+		var started atomic.Bool
+		app := newApp(os.DirFS("testdata/migrations"), fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			started.Store(true)
+			<-ctx.Done()
+			return nil
+		})))
+		Expect(app.Start(context.Background())).To(Succeed())
+
+		Eventually(app.Wait()).Should(Receive(HaveField("ExitCode", 0)))
+		Expect(app.Stop(context.Background())).To(Succeed())
+
+		Expect(appliedVersions()).To(Equal([]int{1, 2}))
+		Expect(started.Load()).To(BeFalse())
+		Expect(logs.FilterMessage("Not starting workers while running a command").Len()).To(Equal(1))
+	})
+
+	It("does not apply migrations while running a command", func() {
+		command = "backfill"
+
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+		Expect(logs.FilterMessage("Not applying migrations while running a command").Len()).To(Equal(1))
+
+		var count int
+		err := open().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'sprout_migrations'").Scan(&count)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("rolls back migrations with the down command", func() {
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+
+		GinkgoT().Setenv("SQL_MIGRATE_COMMAND", "down")
+		GinkgoT().Setenv("SQL_MIGRATE_DOWN_STEPS", "2")
+
+		app := newApp(os.DirFS("testdata/migrations"))
+		Expect(app.Start(context.Background())).To(Succeed())
+		defer func() { _ = app.Stop(context.Background()) }()
+
+		Eventually(app.Wait()).Should(Receive(HaveField("ExitCode", 0)))
+		Expect(appliedVersions()).To(BeEmpty())
+
+		_, err := open().Exec("SELECT * FROM users")
+		Expect(err).To(MatchError(ContainSubstring("no such table")))
+	})
+
+	It("logs the status of migrations with the status command", func() {
+		fsys := fstest.MapFS{
+			"1_create_users.up.sql": {Data: []byte("CREATE TABLE users (id INTEGER);")},
+		}
+		Expect(run(fsys)).To(Succeed())
+
+		fsys["2_create_groups.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE groups (id INTEGER);")}
+		GinkgoT().Setenv("SQL_MIGRATE_COMMAND", "status")
+
+		app := newApp(fsys)
+		Expect(app.Start(context.Background())).To(Succeed())
+		defer func() { _ = app.Stop(context.Background()) }()
+
+		entries := logs.FilterMessage("Migration status").All()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].ContextMap()).To(HaveKeyWithValue("applied", true))
+		Expect(entries[1].ContextMap()).To(HaveKeyWithValue("applied", false))
+		Expect(appliedVersions()).To(Equal([]int{1}))
+	})
+
+	It("rejects migrations with the same version", func() {
+		app := newApp(fstest.MapFS{
+			"1_create_users.up.sql":  {Data: []byte("CREATE TABLE users (id INTEGER);")},
+			"1_create_groups.up.sql": {Data: []byte("CREATE TABLE groups (id INTEGER);")},
+		}, fx.Invoke(func(*migrate.Migrator) {}))
+		Expect(app.Err()).To(MatchError(ContainSubstring("have the same version")))
+	})
+
+	It("can be disabled", func() {
+		GinkgoT().Setenv("SQL_MIGRATE_ENABLED", "false")
+
+		Expect(run(os.DirFS("testdata/migrations"))).To(Succeed())
+
+		_, err := open().Exec("SELECT * FROM users")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// hookRecorder records the names of OnStart hooks in the order they run.
+type hookRecorder struct {
+	names []string
+}
+
+func (r *hookRecorder) LogEvent(event fxevent.Event) {
+	if e, ok := event.(*fxevent.OnStartExecuting); ok {
+		r.names = append(r.names, e.FunctionName)
+	}
+}
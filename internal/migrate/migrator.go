@@ -0,0 +1,274 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Migrator applies and rolls back migrations, keeping track of applied
+// migrations in a table of the database.
+type Migrator struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	db         *sql.DB
+	dialect    dialect
+	table      string
+	migrations []*Migration
+}
+
+// Status is the status of a single migration.
+type Status struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Missing is set if the migration has been applied but its files no
+	// longer exist
+	Missing bool `json:"missing,omitempty"`
+}
+
+type appliedMigration struct {
+	name      string
+	appliedAt time.Time
+}
+
+// Up applies all migrations that have not been applied yet, returning the
+// number of applied migrations.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	ctx, span := m.tracer.Start(ctx, "migrate up")
+	defer span.End()
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[uint64]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := m.apply(ctx, conn, migration, "up", migration.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(
+					ctx,
+					fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%s, %s, %s)", m.table, m.dialect.placeholder(1), m.dialect.placeholder(2), m.dialect.placeholder(3)),
+					int64(migration.Version),
+					migration.Name,
+					time.Now().UTC(),
+				)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return count, err
+	}
+
+	if count == 0 {
+		m.logger.Info("Database schema is up to date")
+	} else {
+		m.logger.Info("Applied migrations", zap.Int("count", count))
+	}
+	return count, nil
+}
+
+// Down rolls back the given number of the most recently applied migrations,
+// returning the number of rolled back migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	ctx, span := m.tracer.Start(ctx, "migrate down")
+	defer span.End()
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[uint64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if !migration.hasDown {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+
+			err := m.apply(ctx, conn, migration, "down", migration.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(
+					ctx,
+					fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.table, m.dialect.placeholder(1)),
+					int64(migration.Version),
+				)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return count, err
+	}
+
+	m.logger.Info("Rolled back migrations", zap.Int("count", count))
+	return count, nil
+}
+
+// Status returns the status of all migrations, including migrations that
+// have been applied but no longer exist.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var result []Status
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[uint64]appliedMigration) error {
+		for _, migration := range m.migrations {
+			status := Status{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+
+			if a, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &a.appliedAt
+				delete(applied, migration.Version)
+			}
+
+			result = append(result, status)
+		}
+
+		for version, a := range applied {
+			result = append(result, Status{
+				Version:   version,
+				Name:      a.name,
+				Applied:   true,
+				AppliedAt: &a.appliedAt,
+				Missing:   true,
+			})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// withLock runs fn on a single connection while holding the migration lock,
+// creating the table of applied migrations if needed.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied map[uint64]appliedMigration) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m.logger.Debug("Acquiring migration lock")
+	unlock, err := m.dialect.lock(ctx, conn, m.table)
+	if err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Release the lock even if the context has been cancelled
+		err = errors.Join(err, unlock(context.WithoutCancel(ctx)))
+	}()
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)",
+		m.table,
+	))
+	if err != nil {
+		return fmt.Errorf("unable to create migration table: %w", err)
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+// applied reads the migrations that have been applied.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version, name, applied_at FROM %s", m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[uint64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		result[uint64(version)] = a
+	}
+	return result, rows.Err()
+}
+
+// apply runs a migration in a transaction together with the update of the
+// table of applied migrations.
+func (m *Migrator) apply(
+	ctx context.Context,
+	conn *sql.Conn,
+	migration *Migration,
+	direction string,
+	statements string,
+	record func(tx *sql.Tx) error,
+) (err error) {
+	ctx, span := m.tracer.Start(ctx, fmt.Sprintf("migration %s %d_%s", direction, migration.Version, migration.Name), trace.WithAttributes(
+		attribute.Int64("migration.version", int64(migration.Version)),
+		attribute.String("migration.name", migration.Name),
+		attribute.String("migration.direction", direction),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	logger := m.logger.With(
+		zap.Uint64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.String("direction", direction),
+	)
+	logger.Info("Running migration")
+	start := time.Now()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Info("Migration completed", zap.Duration("duration", time.Since(start)))
+	return nil
+}
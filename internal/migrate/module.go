@@ -0,0 +1,272 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	// CommandUp applies migrations that have not been applied yet
+	CommandUp = "up"
+	// CommandDown rolls back the most recently applied migrations and exits
+	CommandDown = "down"
+	// CommandStatus logs the status of all migrations and exits
+	CommandStatus = "status"
+)
+
+type Config struct {
+	// Enabled controls if migrations are applied when the application starts
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// Only exits the application once migrations have been applied, for use
+	// in jobs that run before a new version is deployed
+	Only bool `env:"ONLY" envDefault:"false"`
+	// Command is the command to run at startup, one of up, down and status
+	Command string `env:"COMMAND" envDefault:"up"`
+	// DownSteps is the number of migrations rolled back by the down command
+	DownSteps int `env:"DOWN_STEPS" envDefault:"1"`
+
+	// Dialect is the dialect of the database, one of postgres, mysql and
+	// sqlite, determined from the driver if empty
+	Dialect string `env:"DIALECT"`
+	// Table is the table keeping track of applied migrations
+	Table string `env:"TABLE" envDefault:"sprout_migrations"`
+}
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (c Config) validate() error {
+	switch c.Command {
+	case CommandUp, CommandDown, CommandStatus:
+	default:
+		return fmt.Errorf("unsupported migration command %q", c.Command)
+	}
+
+	if c.DownSteps < 1 {
+		return errors.New("SQL_MIGRATE_DOWN_STEPS must be at least 1")
+	}
+
+	if !tableName.MatchString(c.Table) {
+		return fmt.Errorf("invalid migration table name %q", c.Table)
+	}
+
+	return nil
+}
+
+// Module provides a Migrator for the migrations in fsys and the database
+// provided by the SQL module. The migrations are applied by Startup when the
+// application starts.
+func Module(fsys fs.FS) fx.Option {
+	return fx.Module(
+		"sprout:sql:migrate",
+		fx.Provide(config.Config("SQL_MIGRATE", Config{}), fx.Private),
+		fx.Provide(logging.Logger("sql", "migrate"), fx.Private),
+		fx.Provide(func(p params) (*Migrator, error) {
+			return newMigrator(fsys, p)
+		}),
+		fx.Provide(newRun),
+	)
+}
+
+// Startup runs the migrations provided by Module when the application
+// starts. It must be included before modules with OnStart hooks, so that the
+// migrations are applied before workers and schedules start. Running only
+// migrations is treated as a command, so that workers, schedules and servers
+// are not started.
+var Startup = fx.Options(
+	fx.Decorate(decorateCommand),
+	fx.Module(
+		"sprout:sql:migrate:startup",
+		fx.Invoke(register),
+	),
+)
+
+// run is the migration command to run at startup.
+type run struct {
+	logger   *zap.Logger
+	config   Config
+	migrator *Migrator
+}
+
+func newRun(logger *zap.Logger, config Config, migrator *Migrator) *run {
+	return &run{
+		logger:   logger,
+		config:   config,
+		migrator: migrator,
+	}
+}
+
+// enabled returns if the command runs at all.
+func (r *run) enabled() bool {
+	return r.config.Command != CommandUp || r.config.Enabled
+}
+
+// exits returns if the application exits after the command, instead of
+// continuing to start.
+func (r *run) exits() bool {
+	return r.enabled() && (r.config.Command != CommandUp || r.config.Only)
+}
+
+// command returns the name of the command of the service when only
+// migrations are run.
+func (r *run) command() string {
+	return "sql-migrate-" + r.config.Command
+}
+
+type params struct {
+	fx.In
+
+	Logger         *zap.Logger
+	Config         Config
+	DB             *sql.DB
+	DriverName     string `name:"sql:driver"`
+	TracerProvider trace.TracerProvider
+}
+
+func newMigrator(fsys fs.FS, p params) (*Migrator, error) {
+	if err := p.Config.validate(); err != nil {
+		return nil, err
+	}
+
+	d, err := findDialect(p.Config.Dialect, p.DriverName)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		logger:     p.Logger,
+		tracer:     p.TracerProvider.Tracer("sprout/migrate"),
+		db:         p.DB,
+		dialect:    d,
+		table:      p.Config.Table,
+		migrations: migrations,
+	}, nil
+}
+
+type commandParams struct {
+	fx.In
+
+	Command string `name:"service:command"`
+	Run     *run   `optional:"true"`
+}
+
+type commandResult struct {
+	fx.Out
+
+	Command string `name:"service:command"`
+}
+
+// decorateCommand sets the command of the service when only migrations are
+// run, in the same way as for other commands.
+func decorateCommand(p commandParams) commandResult {
+	if p.Command != "" || p.Run == nil || !p.Run.exits() {
+		return commandResult{Command: p.Command}
+	}
+
+	return commandResult{Command: p.Run.command()}
+}
+
+type registerParams struct {
+	fx.In
+
+	Lifecycle  fx.Lifecycle
+	Shutdowner fx.Shutdowner
+	Checks     health.Checks
+	Command    string `name:"service:command" optional:"true"`
+	Run        *run   `optional:"true"`
+}
+
+func register(p registerParams) {
+	r := p.Run
+	if r == nil {
+		return
+	}
+
+	if p.Command != "" && p.Command != r.command() {
+		r.logger.Info("Not applying migrations while running a command", zap.String("command", p.Command))
+		return
+	}
+
+	if !r.enabled() {
+		r.logger.Info("Migrations are disabled")
+		return
+	}
+
+	var migrated atomic.Bool
+	p.Checks.AddReadinessCheck(health.Check{
+		Name:      "sql-migrations",
+		DependsOn: []string{"sql"},
+		Check: func(ctx context.Context) error {
+			if !migrated.Load() {
+				return errors.New("migrations have not been applied")
+			}
+			return nil
+		},
+	})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			switch r.config.Command {
+			case CommandDown:
+				_, err := r.migrator.Down(ctx, r.config.DownSteps)
+				if err != nil {
+					return err
+				}
+			case CommandStatus:
+				status, err := r.migrator.Status(ctx)
+				if err != nil {
+					return err
+				}
+				logStatus(r.logger, status)
+			default:
+				_, err := r.migrator.Up(ctx)
+				if err != nil {
+					return err
+				}
+
+				migrated.Store(true)
+			}
+
+			if !r.exits() {
+				return nil
+			}
+
+			r.logger.Info("Migration command completed, exiting", zap.String("command", r.config.Command))
+			return p.Shutdowner.Shutdown(fx.ExitCode(0))
+		},
+	})
+}
+
+func logStatus(logger *zap.Logger, status []Status) {
+	for _, s := range status {
+		fields := []zap.Field{
+			zap.Uint64("version", s.Version),
+			zap.String("name", s.Name),
+			zap.Bool("applied", s.Applied),
+		}
+		if s.AppliedAt != nil {
+			fields = append(fields, zap.Time("appliedAt", *s.AppliedAt))
+		}
+		if s.Missing {
+			fields = append(fields, zap.Bool("missing", true))
+		}
+
+		logger.Info("Migration status", fields...)
+	}
+}
@@ -0,0 +1 @@
+CREATE TABLE b (id INTEGER;
@@ -0,0 +1,131 @@
+// Package module provides modules with a logger, tracer, meter and
+// configuration prefix scoped to the module.
+package module
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Info describes a module. It is available within the module and all
+// modules are listed in the group sprout:modules.
+type Info struct {
+	// Name of the module
+	Name string `json:"name"`
+	// Prefix of environment variables used to configure the module
+	Prefix string `json:"prefix"`
+	// Enabled is false if the module has been disabled via the environment
+	Enabled bool `json:"enabled"`
+}
+
+func (i Info) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", i.Name)
+	enc.AddString("prefix", i.Prefix)
+	enc.AddBool("enabled", i.Enabled)
+	return nil
+}
+
+// Prefix derives the configuration prefix of a module from its name, such as
+// USER_BILLING for user-billing.
+func Prefix(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// New creates a module with the given options. The module can be disabled
+// by setting MODULE_<PREFIX>_ENABLED to false, in which case none of the
+// options are used.
+func New(name string, options ...fx.Option) fx.Option {
+	info := Info{
+		Name:    name,
+		Prefix:  Prefix(name),
+		Enabled: true,
+	}
+
+	key := "MODULE_" + info.Prefix + "_ENABLED"
+	if value, ok := os.LookupEnv(key); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fx.Error(fmt.Errorf("invalid value for %s: %w", key, err))
+		}
+		info.Enabled = enabled
+	}
+
+	register := fx.Provide(fx.Annotate(
+		func() Info { return info },
+		fx.ResultTags(`group:"sprout:modules"`),
+	))
+
+	if !info.Enabled {
+		return fx.Module(name, register)
+	}
+
+	return fx.Module(
+		name,
+		register,
+		fx.Supply(info, fx.Private),
+		fx.Provide(logging.Logger(name), fx.Private),
+		fx.Provide(func(tp trace.TracerProvider) trace.Tracer {
+			return tp.Tracer(name)
+		}, fx.Private),
+		fx.Provide(func(mp metric.MeterProvider) metric.Meter {
+			return mp.Meter(name)
+		}, fx.Private),
+		fx.Options(options...),
+	)
+}
+
+// Config creates a constructor that reads configuration using the prefix of
+// the module it is provided in.
+func Config[T any](value T) any {
+	return func(info Info, in config.In) (T, error) {
+		return config.Load(info.Prefix, value, in)
+	}
+}
+
+// Sorted returns the modules sorted by name.
+func Sorted(modules []Info) []Info {
+	sorted := slices.Clone(modules)
+	slices.SortFunc(sorted, func(a, b Info) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted
+}
+
+type listParams struct {
+	fx.In
+
+	Logger  *zap.Logger
+	Modules []Info `group:"sprout:modules"`
+}
+
+// Listing logs the modules of the application when it is created.
+var Listing = fx.Module(
+	"sprout:modules",
+	fx.Provide(logging.Logger("modules"), fx.Private),
+	fx.Invoke(func(p listParams) {
+		if len(p.Modules) == 0 {
+			return
+		}
+
+		p.Logger.Info("Modules", zap.Objects("modules", Sorted(p.Modules)))
+	}),
+)
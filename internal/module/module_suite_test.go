@@ -0,0 +1,13 @@
+package module_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestModule(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Module Suite")
+}
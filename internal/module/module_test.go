@@ -0,0 +1,157 @@
+package module_test
+
+import (
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/module"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type Config struct {
+	Currency string `env:"CURRENCY" envDefault:"EUR"`
+}
+
+var _ = Describe("Module", func() {
+	newApp := func(options ...fx.Option) *fxtest.App {
+		return fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			fx.Supply(
+				fx.Annotate(tracenoop.NewTracerProvider(), fx.As(new(trace.TracerProvider))),
+				fx.Annotate(metricnoop.NewMeterProvider(), fx.As(new(metric.MeterProvider))),
+			),
+			fx.Options(options...),
+		)
+	}
+
+	It("derives the prefix from the name", func() {
+		Expect(module.Prefix("billing")).To(Equal("BILLING"))
+		Expect(module.Prefix("user-billing")).To(Equal("USER_BILLING"))
+		Expect(module.Prefix("Search.v2")).To(Equal("SEARCH_V2"))
+	})
+
+	It("provides a logger, tracer and meter within the module", func() {
+		var info module.Info
+		var logger *zap.Logger
+		var tracer trace.Tracer
+		var meter metric.Meter
+
+		app := newApp(module.New(
+			"billing",
+			fx.Populate(&info, &logger, &tracer, &meter),
+		))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(info).To(Equal(module.Info{Name: "billing", Prefix: "BILLING", Enabled: true}))
+		Expect(logger).ToNot(BeNil())
+		Expect(tracer).ToNot(BeNil())
+		Expect(meter).ToNot(BeNil())
+	})
+
+	It("keeps the logger private to the module", func() {
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			module.New("billing"),
+			fx.Invoke(func(*zap.Logger) {}),
+		)
+		Expect(app.Err()).To(HaveOccurred())
+	})
+
+	It("reads configuration using the prefix of the module", func() {
+		GinkgoT().Setenv("BILLING_CURRENCY", "SEK")
+		GinkgoT().Setenv("SHIPPING_CURRENCY", "NOK")
+
+		var billing, shipping Config
+		app := newApp(
+			module.New(
+				"billing",
+				fx.Provide(module.Config(Config{}), fx.Private),
+				fx.Populate(&billing),
+			),
+			module.New(
+				"shipping",
+				fx.Provide(module.Config(Config{}), fx.Private),
+				fx.Populate(&shipping),
+			),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(billing.Currency).To(Equal("SEK"))
+		Expect(shipping.Currency).To(Equal("NOK"))
+	})
+
+	It("uses the innermost module when nested", func() {
+		var outer, inner module.Info
+		app := newApp(module.New(
+			"billing",
+			fx.Populate(&outer),
+			module.New("invoices", fx.Populate(&inner)),
+		))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(outer.Name).To(Equal("billing"))
+		Expect(inner.Name).To(Equal("invoices"))
+	})
+
+	It("can be disabled via the environment", func() {
+		GinkgoT().Setenv("MODULE_BILLING_ENABLED", "false")
+
+		invoked := false
+		var modules []module.Info
+		app := newApp(
+			module.New("billing", fx.Invoke(func() {
+				invoked = true
+			})),
+			module.New("shipping"),
+			fx.Invoke(fx.Annotate(func(m []module.Info) {
+				modules = module.Sorted(m)
+			}, fx.ParamTags(`group:"sprout:modules"`))),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(invoked).To(BeFalse())
+		Expect(modules).To(Equal([]module.Info{
+			{Name: "billing", Prefix: "BILLING", Enabled: false},
+			{Name: "shipping", Prefix: "SHIPPING", Enabled: true},
+		}))
+	})
+
+	It("fails if enabled is invalid", func() {
+		GinkgoT().Setenv("MODULE_BILLING_ENABLED", "maybe")
+
+		app := fx.New(fx.NopLogger, module.New("billing"))
+		Expect(app.Err()).To(MatchError(ContainSubstring("MODULE_BILLING_ENABLED")))
+	})
+
+	It("lists modules when created", func() {
+		core, logs := observer.New(zap.InfoLevel)
+		app := fxtest.New(
+			GinkgoT(),
+			logging.Module(zap.New(core)),
+			module.Listing,
+			module.New("billing"),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		entries := logs.FilterMessage("Modules").All()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].ContextMap()["modules"]).To(Equal([]any{
+			map[string]any{"name": "billing", "prefix": "BILLING", "enabled": true},
+		}))
+	})
+})
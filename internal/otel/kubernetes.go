@@ -0,0 +1,67 @@
+package otel
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// kubernetesDetector detects attributes describing the pod the service runs
+// in. Kubernetes does not expose these automatically, so they are read from
+// environment variables that are usually populated via the downward API.
+type kubernetesDetector struct{}
+
+var _ resource.Detector = kubernetesDetector{}
+
+func (kubernetesDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return resource.Empty(), nil
+	}
+
+	var attributes []attribute.KeyValue
+
+	podName := firstEnv("K8S_POD_NAME", "POD_NAME")
+	if podName == "" {
+		// Pods use their name as the hostname by default
+		podName, _ = os.Hostname()
+	}
+	if podName != "" {
+		attributes = append(attributes, semconv.K8SPodName(podName))
+	}
+
+	namespace := firstEnv("K8S_NAMESPACE_NAME", "POD_NAMESPACE")
+	if namespace == "" {
+		data, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+	if namespace != "" {
+		attributes = append(attributes, semconv.K8SNamespaceName(namespace))
+	}
+
+	if nodeName := firstEnv("K8S_NODE_NAME", "NODE_NAME"); nodeName != "" {
+		attributes = append(attributes, semconv.K8SNodeName(nodeName))
+	}
+
+	if podUID := firstEnv("K8S_POD_UID", "POD_UID"); podUID != "" {
+		attributes = append(attributes, semconv.K8SPodUID(podUID))
+	}
+
+	return resource.NewSchemaless(attributes...), nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
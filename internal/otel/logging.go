@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/shutdown"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
@@ -11,9 +12,11 @@ import (
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 )
 
-// InitLogging initializes OpenTelemetry log exporting if configured.
+// InitLogging initializes OpenTelemetry log exporting if configured. The
+// provider is shut down by the coordinator.
 func InitLogging(
 	serviceInfo internal.ServiceInfo,
+	coordinator *shutdown.Coordinator,
 ) (log.LoggerProvider, bool, error) {
 	if !hasExporterEndpoint(moduleLogging) {
 		return noop.NewLoggerProvider(), false, nil
@@ -41,6 +44,7 @@ func InitLogging(
 
 	provider := sdklog.NewLoggerProvider(options...)
 	global.SetLoggerProvider(provider)
+	coordinator.Register("logging", provider.Shutdown)
 
 	return provider, true, nil
 }
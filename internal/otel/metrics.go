@@ -3,21 +3,21 @@ package otel
 import (
 	"context"
 
+	"github.com/aholstenson/sprout-go/internal/shutdown"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
-	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
 // SetupMetrics configures OpenTelemetry metrics.
 func SetupMetrics(
 	resource *resource.Resource,
-	lifecycle fx.Lifecycle,
 	logger *zap.Logger,
+	coordinator *shutdown.Coordinator,
 ) (metric.MeterProvider, error) {
 	if !hasExporterEndpoint(moduleMetrics) {
 		// If no endpoint is set, we don't want to send any metrics to the
@@ -37,12 +37,7 @@ func SetupMetrics(
 		sdkmetric.WithResource(resource),
 	)
 	otel.SetMeterProvider(provider)
-
-	lifecycle.Append(fx.Hook{
-		OnStop: func(ctx context.Context) error {
-			return provider.Shutdown(ctx)
-		},
-	})
+	coordinator.Register("metrics", provider.Shutdown)
 
 	return provider, nil
 }
@@ -2,8 +2,10 @@ package otel
 
 import (
 	"context"
+	"errors"
 	"os"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/fx"
@@ -17,18 +19,38 @@ type ServiceInfo struct {
 
 	Development bool `name:"env:development"`
 	Testing     bool `name:"env:testing"`
+
+	// Attributes are additional resource attributes provided by other parts
+	// of Sprout, such as the runtime settings in effect.
+	Attributes []attribute.KeyValue `group:"otel:resource"`
 }
 
 func CreateResource(service ServiceInfo) (*resource.Resource, error) {
-	return resource.New(
+	res, err := resource.New(
 		context.Background(),
 		resource.WithAttributes(
 			semconv.ServiceNameKey.String(service.Name),
 			semconv.ServiceVersionKey.String(service.Version),
 		),
+		resource.WithAttributes(service.Attributes...),
+		resource.WithHost(),
+		resource.WithOS(),
+		// Command line arguments are not included as they may contain secrets
+		resource.WithProcessPID(),
+		resource.WithProcessExecutableName(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithProcessRuntimeDescription(),
+		resource.WithContainer(),
+		resource.WithDetectors(kubernetesDetector{}),
 		resource.WithFromEnv(),
 		resource.WithTelemetrySDK(),
 	)
+	if errors.Is(err, resource.ErrPartialResource) {
+		// Some detectors failing should not prevent the service from starting
+		return res, nil
+	}
+	return res, err
 }
 
 type module string
@@ -3,6 +3,7 @@ package otel
 import (
 	"context"
 
+	"github.com/aholstenson/sprout-go/internal/shutdown"
 	"github.com/caarlos0/env/v11"
 	"go.opentelemetry.io/contrib/propagators/autoprop"
 	"go.opentelemetry.io/otel"
@@ -29,6 +30,7 @@ func SetupTracing(
 	resource *resource.Resource,
 	lifecycle fx.Lifecycle,
 	logger *zap.Logger,
+	coordinator *shutdown.Coordinator,
 ) (trace.TracerProvider, error) {
 	// Set a better default for propagators, since the default is a no-op
 	otel.SetTextMapPropagator(autoprop.NewTextMapPropagator())
@@ -90,12 +92,7 @@ func SetupTracing(
 		sdktrace.WithSampler(sampler),
 	)
 	otel.SetTracerProvider(tp)
-
-	lifecycle.Append(fx.Hook{
-		OnStop: func(ctx context.Context) error {
-			return tp.Shutdown(ctx)
-		},
-	})
+	coordinator.Register("tracing", tp.Shutdown)
 
 	return tp, nil
 }
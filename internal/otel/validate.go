@@ -0,0 +1,94 @@
+package otel
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// exporterSignals are the signals with their own OTLP exporter settings, in
+// addition to the shared OTEL_EXPORTER_OTLP_ settings.
+var exporterSignals = []string{"", "TRACES_", "METRICS_", "LOGS_"}
+
+// ValidateExporterConfig checks that the OTLP exporter settings in the
+// environment can be parsed. The exporters only log invalid settings and
+// fall back to defaults, so this is used to catch them before deploying.
+func ValidateExporterConfig() []error {
+	var errs []error
+	for _, signal := range exporterSignals {
+		prefix := "OTEL_EXPORTER_OTLP_" + signal
+
+		if value, ok := os.LookupEnv(prefix + "ENDPOINT"); ok && value != "" {
+			if err := validateEndpoint(value); err != nil {
+				errs = append(errs, fmt.Errorf("%sENDPOINT: %w", prefix, err))
+			}
+		}
+
+		if value, ok := os.LookupEnv(prefix + "HEADERS"); ok && value != "" {
+			if err := validateHeaders(value); err != nil {
+				errs = append(errs, fmt.Errorf("%sHEADERS: %w", prefix, err))
+			}
+		}
+
+		if value, ok := os.LookupEnv(prefix + "TIMEOUT"); ok && value != "" {
+			if timeout, err := strconv.Atoi(value); err != nil || timeout < 0 {
+				errs = append(errs, fmt.Errorf("%sTIMEOUT: must be a number of milliseconds", prefix))
+			}
+		}
+
+		if value, ok := os.LookupEnv(prefix + "INSECURE"); ok && value != "" {
+			if _, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("%sINSECURE: must be true or false", prefix))
+			}
+		}
+
+		if value, ok := os.LookupEnv(prefix + "COMPRESSION"); ok && value != "" {
+			if value != "gzip" && value != "none" {
+				errs = append(errs, fmt.Errorf("%sCOMPRESSION: must be gzip or none", prefix))
+			}
+		}
+
+		if value, ok := os.LookupEnv(prefix + "CERTIFICATE"); ok && value != "" {
+			if _, err := os.Stat(value); err != nil {
+				errs = append(errs, fmt.Errorf("%sCERTIFICATE: %w", prefix, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateEndpoint(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must be a URL with the scheme http or https", value)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%q is missing a host", value)
+	}
+
+	return nil
+}
+
+func validateHeaders(value string) error {
+	// Values are not included in errors as headers often contain secrets
+	for i, header := range strings.Split(value, ",") {
+		key, headerValue, found := strings.Cut(header, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("header %d is not in the format key=value", i+1)
+		}
+
+		if _, err := url.PathUnescape(headerValue); err != nil {
+			return fmt.Errorf("value of %q is not URL encoded", strings.TrimSpace(key))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package profiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// directoryExporter writes profiles to a local directory, removing the
+// oldest profiles of a type when there are more than the configured number
+// of files.
+type directoryExporter struct {
+	directory   string
+	maxFiles    int
+	serviceName string
+}
+
+func newDirectoryExporter(config Config, serviceName string) *directoryExporter {
+	return &directoryExporter{
+		directory:   config.Directory,
+		maxFiles:    config.MaxFiles,
+		serviceName: serviceName,
+	}
+}
+
+func (e *directoryExporter) Export(ctx context.Context, profile profile) error {
+	err := os.MkdirAll(e.directory, 0o750)
+	if err != nil {
+		return err
+	}
+
+	prefix := e.serviceName + "-" + profile.Type + "-"
+	// The timestamp format sorts lexically, which is used during rotation
+	name := prefix + profile.Start.UTC().Format("20060102T150405.000Z") + ".pb.gz"
+	err = os.WriteFile(filepath.Join(e.directory, name), profile.Data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return e.rotate(prefix)
+}
+
+func (e *directoryExporter) rotate(prefix string) error {
+	if e.maxFiles <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(e.directory)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			files = append(files, entry.Name())
+		}
+	}
+
+	if len(files) <= e.maxFiles {
+		return nil
+	}
+
+	sort.Strings(files)
+	for _, file := range files[:len(files)-e.maxFiles] {
+		err = os.Remove(filepath.Join(e.directory, file))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Enabled controls if profiles are captured continuously
+	Enabled bool `env:"ENABLED" envDefault:"false"`
+
+	// Types are the profiles to capture, any of cpu, heap, goroutine, mutex
+	// and block
+	Types []string `env:"TYPES" envDefault:"cpu,heap,goroutine,mutex" envSeparator:","`
+	// Interval is how often profiles are captured
+	Interval time.Duration `env:"INTERVAL" envDefault:"60s"`
+	// CPUDuration is how long the CPU is profiled for every interval
+	CPUDuration time.Duration `env:"CPU_DURATION" envDefault:"10s"`
+	// MutexFraction is the fraction of mutex contention events reported
+	MutexFraction int `env:"MUTEX_FRACTION" envDefault:"5"`
+	// BlockRate is the rate in nanoseconds at which blocking events are
+	// sampled
+	BlockRate int `env:"BLOCK_RATE" envDefault:"10000"`
+
+	// Endpoint is the URL of a Pyroscope compatible server to push to
+	Endpoint string `env:"ENDPOINT"`
+	// AuthToken is sent as a bearer token when pushing profiles
+	AuthToken string `env:"AUTH_TOKEN,unset"`
+	// Timeout is the timeout of pushing a single profile
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"10s"`
+
+	// Directory is a local directory to write profiles to instead of pushing
+	// them to a server
+	Directory string `env:"DIRECTORY"`
+	// MaxFiles is the number of files kept per profile type in Directory
+	MaxFiles int `env:"MAX_FILES" envDefault:"10"`
+
+	// Tags are additional tags added to profiles, in the format key:value
+	Tags map[string]string `env:"TAGS" envSeparator:"," envKeyValSeparator:":"`
+}
+
+func (c Config) validate() error {
+	if c.Endpoint == "" && c.Directory == "" {
+		return errors.New("profiler requires PROFILER_ENDPOINT or PROFILER_DIRECTORY to be set")
+	}
+
+	if c.Endpoint != "" && c.Directory != "" {
+		return errors.New("only one of PROFILER_ENDPOINT and PROFILER_DIRECTORY can be set")
+	}
+
+	if c.Interval <= 0 {
+		return errors.New("PROFILER_INTERVAL must be positive")
+	}
+
+	for _, profileType := range c.Types {
+		if !isSupportedType(profileType) {
+			return fmt.Errorf("unsupported profile type %q", profileType)
+		}
+
+		if profileType == typeCPU && (c.CPUDuration <= 0 || c.CPUDuration >= c.Interval) {
+			return errors.New("PROFILER_CPU_DURATION must be positive and less than PROFILER_INTERVAL")
+		}
+	}
+
+	return nil
+}
+
+var Module = fx.Module(
+	"sprout:profiler",
+	fx.Provide(config.Config("PROFILER", Config{}), fx.Private),
+	fx.Provide(logging.Logger("profiler"), fx.Private),
+	fx.Invoke(register),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    Config
+
+	ServiceName    string `name:"service:name"`
+	ServiceVersion string `name:"service:version"`
+}
+
+func register(p params) error {
+	if !p.Config.Enabled {
+		return nil
+	}
+
+	if err := p.Config.validate(); err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"service_version": p.ServiceVersion,
+	}
+	for key, value := range p.Config.Tags {
+		tags[key] = value
+	}
+
+	var exporter exporter
+	if p.Config.Endpoint != "" {
+		exporter = newPyroscopeExporter(p.Config, p.ServiceName, tags)
+	} else {
+		exporter = newDirectoryExporter(p.Config, p.ServiceName)
+	}
+
+	profiler := newProfiler(p.Logger, p.Config, exporter)
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return profiler.Start()
+		},
+		OnStop: profiler.Stop,
+	})
+	return nil
+}
@@ -0,0 +1,174 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	goruntime "runtime"
+	"runtime/pprof"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	typeCPU       = "cpu"
+	typeHeap      = "heap"
+	typeGoroutine = "goroutine"
+	typeMutex     = "mutex"
+	typeBlock     = "block"
+)
+
+func isSupportedType(profileType string) bool {
+	switch profileType {
+	case typeCPU, typeHeap, typeGoroutine, typeMutex, typeBlock:
+		return true
+	}
+
+	return false
+}
+
+// profile is a captured profile in the gzipped protobuf format of pprof.
+type profile struct {
+	Type  string
+	Start time.Time
+	End   time.Time
+	Data  []byte
+}
+
+type exporter interface {
+	Export(ctx context.Context, profile profile) error
+}
+
+// profiler periodically captures profiles and hands them to an exporter.
+type profiler struct {
+	logger   *zap.Logger
+	config   Config
+	exporter exporter
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newProfiler(logger *zap.Logger, config Config, exporter exporter) *profiler {
+	return &profiler{
+		logger:   logger,
+		config:   config,
+		exporter: exporter,
+	}
+}
+
+func (p *profiler) Start() error {
+	p.logger.Info(
+		"Starting continuous profiling",
+		zap.Strings("types", p.config.Types),
+		zap.Duration("interval", p.config.Interval),
+	)
+
+	if slices.Contains(p.config.Types, typeMutex) {
+		goruntime.SetMutexProfileFraction(p.config.MutexFraction)
+	}
+
+	if slices.Contains(p.config.Types, typeBlock) {
+		goruntime.SetBlockProfileRate(p.config.BlockRate)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go p.run(ctx)
+	return nil
+}
+
+func (p *profiler) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+
+	p.logger.Info("Stopping continuous profiling")
+	p.cancel()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if slices.Contains(p.config.Types, typeMutex) {
+		goruntime.SetMutexProfileFraction(0)
+	}
+
+	if slices.Contains(p.config.Types, typeBlock) {
+		goruntime.SetBlockProfileRate(0)
+	}
+
+	return nil
+}
+
+func (p *profiler) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.captureAll(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *profiler) captureAll(ctx context.Context) {
+	for _, profileType := range p.config.Types {
+		profile, err := p.capture(ctx, profileType)
+		if ctx.Err() != nil {
+			return
+		} else if err != nil {
+			p.logger.Warn("Unable to capture profile", zap.String("type", profileType), zap.Error(err))
+			continue
+		}
+
+		exportCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+		err = p.exporter.Export(exportCtx, profile)
+		cancel()
+		if err != nil {
+			p.logger.Warn("Unable to export profile", zap.String("type", profileType), zap.Error(err))
+			continue
+		}
+
+		p.logger.Debug("Exported profile", zap.String("type", profileType), zap.Int("bytes", len(profile.Data)))
+	}
+}
+
+func (p *profiler) capture(ctx context.Context, profileType string) (profile, error) {
+	var buf bytes.Buffer
+	start := time.Now()
+
+	if profileType == typeCPU {
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return profile{}, err
+		}
+
+		select {
+		case <-time.After(p.config.CPUDuration):
+		case <-ctx.Done():
+		}
+		pprof.StopCPUProfile()
+	} else {
+		if err := pprof.Lookup(profileType).WriteTo(&buf, 0); err != nil {
+			return profile{}, err
+		}
+	}
+
+	return profile{
+		Type:  profileType,
+		Start: start,
+		End:   time.Now(),
+		Data:  buf.Bytes(),
+	}, nil
+}
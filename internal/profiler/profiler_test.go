@@ -0,0 +1,120 @@
+package profiler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/profiler"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Profiler", func() {
+	serviceInfo := fx.Supply(internal.ServiceInfo{
+		Name:    "test",
+		Version: "v1.0.0",
+		Testing: true,
+	})
+
+	It("pushes profiles to a Pyroscope compatible endpoint", func() {
+		var mutex sync.Mutex
+		var names []string
+		var authorization string
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			file, _, err := r.FormFile("profile")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = file.Close()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			names = append(names, r.URL.Query().Get("name"))
+			authorization = r.Header.Get("Authorization")
+		}))
+		defer receiver.Close()
+
+		t := GinkgoT()
+		t.Setenv("PROFILER_ENABLED", "true")
+		t.Setenv("PROFILER_ENDPOINT", receiver.URL)
+		t.Setenv("PROFILER_AUTH_TOKEN", "secret")
+		t.Setenv("PROFILER_TYPES", "cpu,heap,goroutine")
+		t.Setenv("PROFILER_INTERVAL", "200ms")
+		t.Setenv("PROFILER_CPU_DURATION", "50ms")
+		t.Setenv("PROFILER_TAGS", "region:eu")
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(t)),
+			serviceInfo,
+			profiler.Module,
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(func(g Gomega) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			g.Expect(names).To(ContainElements(
+				"test.cpu{region=eu,service_version=v1.0.0}",
+				"test.heap{region=eu,service_version=v1.0.0}",
+				"test.goroutine{region=eu,service_version=v1.0.0}",
+			))
+			g.Expect(authorization).To(Equal("Bearer secret"))
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+	})
+
+	It("writes profiles to a rotating directory", func() {
+		t := GinkgoT()
+		directory := t.TempDir()
+		t.Setenv("PROFILER_ENABLED", "true")
+		t.Setenv("PROFILER_DIRECTORY", directory)
+		t.Setenv("PROFILER_TYPES", "heap")
+		t.Setenv("PROFILER_INTERVAL", "20ms")
+		t.Setenv("PROFILER_MAX_FILES", "2")
+
+		app := fxtest.New(
+			t,
+			logging.Module(zaptest.NewLogger(t)),
+			serviceInfo,
+			profiler.Module,
+		)
+		app.RequireStart()
+
+		// Wait for enough profiles to trigger rotation
+		time.Sleep(200 * time.Millisecond)
+		app.RequireStop()
+
+		entries, err := os.ReadDir(directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		for _, entry := range entries {
+			Expect(strings.HasPrefix(entry.Name(), "test-heap-")).To(BeTrue())
+			Expect(filepath.Ext(entry.Name())).To(Equal(".gz"))
+		}
+	})
+
+	It("requires a destination when enabled", func() {
+		t := GinkgoT()
+		t.Setenv("PROFILER_ENABLED", "true")
+
+		app := fx.New(
+			fx.NopLogger,
+			logging.Module(zaptest.NewLogger(t)),
+			serviceInfo,
+			profiler.Module,
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("PROFILER_ENDPOINT")))
+	})
+})
@@ -0,0 +1,102 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pyroscopeExporter pushes profiles to the ingest API of a Pyroscope
+// compatible server.
+type pyroscopeExporter struct {
+	client    *http.Client
+	endpoint  string
+	authToken string
+
+	serviceName string
+	tags        map[string]string
+}
+
+func newPyroscopeExporter(config Config, serviceName string, tags map[string]string) *pyroscopeExporter {
+	return &pyroscopeExporter{
+		client:      &http.Client{},
+		endpoint:    strings.TrimSuffix(config.Endpoint, "/") + "/ingest",
+		authToken:   config.AuthToken,
+		serviceName: serviceName,
+		tags:        tags,
+	}
+}
+
+func (e *pyroscopeExporter) Export(ctx context.Context, profile profile) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+
+	_, err = part.Write(profile.Data)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("name", e.name(profile.Type))
+	query.Set("from", strconv.FormatInt(profile.Start.Unix(), 10))
+	query.Set("until", strconv.FormatInt(profile.End.Unix(), 10))
+	query.Set("format", "pprof")
+	query.Set("spyName", "gospy")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+"?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if e.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+e.authToken)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// name builds the application name in the format used by Pyroscope, such
+// as service.cpu{service_version=v1.0.0}.
+func (e *pyroscopeExporter) name(profileType string) string {
+	keys := make([]string, 0, len(e.tags))
+	for key := range e.tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, key+"="+e.tags[key])
+	}
+
+	return e.serviceName + "." + profileType + "{" + strings.Join(tags, ",") + "}"
+}
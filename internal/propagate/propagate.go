@@ -0,0 +1,62 @@
+// Package propagate carries trace context and baggage across process
+// boundaries and into background work.
+package propagate
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Inject writes the trace context and baggage of ctx to carrier using the
+// global propagator.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// Extract returns a copy of ctx with the trace context and baggage read from
+// carrier using the global propagator.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// WithBaggage returns a copy of ctx with a baggage entry set, replacing any
+// existing entry with the same key.
+func WithBaggage(ctx context.Context, key string, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, err
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, err
+	}
+
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// Baggage returns the value of a baggage entry, or an empty string if the
+// entry is not set.
+func Baggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// Detach returns a context that is not canceled when ctx is, and has no
+// deadline, for work that outlives the operation of ctx. Values such as
+// baggage and log fields are kept. The current span is replaced with its
+// span context, so that spans started from the returned context are part of
+// the same trace without the detached work modifying a span that may already
+// have ended.
+func Detach(ctx context.Context) context.Context {
+	detached := context.WithoutCancel(ctx)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		detached = trace.ContextWithSpanContext(detached, spanContext)
+	}
+	return detached
+}
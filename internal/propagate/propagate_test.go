@@ -0,0 +1,89 @@
+package propagate_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/propagate"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Propagate", func() {
+	var tracer trace.Tracer
+
+	BeforeEach(func() {
+		previous := otel.GetTextMapPropagator()
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		))
+		DeferCleanup(func() {
+			otel.SetTextMapPropagator(previous)
+		})
+
+		tracer = sdktrace.NewTracerProvider().Tracer("test")
+	})
+
+	It("injects and extracts trace context and baggage", func() {
+		ctx, span := tracer.Start(context.Background(), "publish")
+		defer span.End()
+
+		ctx, err := propagate.WithBaggage(ctx, "tenant", "acme corp")
+		Expect(err).ToNot(HaveOccurred())
+
+		headers := map[string]string{}
+		propagate.Inject(ctx, propagation.MapCarrier(headers))
+		Expect(headers).To(HaveKey("traceparent"))
+		Expect(headers).To(HaveKeyWithValue("baggage", "tenant=acme%20corp"))
+
+		received := propagate.Extract(context.Background(), propagation.MapCarrier(headers))
+		Expect(trace.SpanContextFromContext(received).TraceID()).To(Equal(span.SpanContext().TraceID()))
+		Expect(propagate.Baggage(received, "tenant")).To(Equal("acme corp"))
+	})
+
+	It("replaces baggage entries", func() {
+		ctx, err := propagate.WithBaggage(context.Background(), "tenant", "first")
+		Expect(err).ToNot(HaveOccurred())
+		ctx, err = propagate.WithBaggage(ctx, "tenant", "second")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(propagate.Baggage(ctx, "tenant")).To(Equal("second"))
+		Expect(propagate.Baggage(ctx, "unknown")).To(BeEmpty())
+	})
+
+	It("rejects empty baggage keys", func() {
+		ctx := context.Background()
+		result, err := propagate.WithBaggage(ctx, "", "value")
+		Expect(err).To(HaveOccurred())
+		Expect(result).To(Equal(ctx))
+	})
+
+	It("detaches from cancellation but keeps the trace", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		ctx, span := tracer.Start(ctx, "request")
+		ctx, err := propagate.WithBaggage(ctx, "tenant", "acme")
+		Expect(err).ToNot(HaveOccurred())
+
+		detached := propagate.Detach(ctx)
+		cancel()
+		span.End()
+
+		Expect(ctx.Err()).To(HaveOccurred())
+		Expect(detached.Err()).ToNot(HaveOccurred())
+		_, hasDeadline := detached.Deadline()
+		Expect(hasDeadline).To(BeFalse())
+
+		Expect(propagate.Baggage(detached, "tenant")).To(Equal("acme"))
+		Expect(trace.SpanContextFromContext(detached)).To(Equal(span.SpanContext()))
+		Expect(trace.SpanFromContext(detached).IsRecording()).To(BeFalse())
+
+		_, child := tracer.Start(detached, "background")
+		defer child.End()
+		Expect(child.SpanContext().TraceID()).To(Equal(span.SpanContext().TraceID()))
+	})
+})
@@ -0,0 +1,15 @@
+package runtime
+
+import "github.com/KimMachineGun/automemlimit/memlimit"
+
+// SetMemoryProvider replaces the memory limit provider of the tuner, making
+// it possible to test changes to the available memory.
+func SetMemoryProvider(t *Tuner, provider memlimit.Provider) {
+	t.memoryProvider = provider
+	t.manageMemoryLimit = true
+}
+
+// Refresh re-evaluates the limits of the tuner.
+func Refresh(t *Tuner) {
+	t.refresh()
+}
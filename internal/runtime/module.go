@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+)
+
+// Module reports the runtime settings of the tuner as resource attributes
+// and metrics, and re-evaluates limits while the application is running.
+func Module(tuner *Tuner) fx.Option {
+	return fx.Module(
+		"sprout:runtime",
+		fx.Supply(tuner),
+		fx.Provide(fx.Annotate(
+			tuner.Attributes,
+			fx.ResultTags(`group:"otel:resource,flatten"`),
+		)),
+		fx.Invoke(func(lifecycle fx.Lifecycle, mp metric.MeterProvider) error {
+			err := tuner.registerMetrics(mp.Meter("sprout/runtime"))
+			if err != nil {
+				return err
+			}
+
+			lifecycle.Append(fx.Hook{
+				OnStart: func(ctx context.Context) error {
+					tuner.Start()
+					return nil
+				},
+				OnStop: tuner.Stop,
+			})
+			return nil
+		}),
+	)
+}
+
+// Attributes returns the runtime settings in effect as resource attributes.
+func (t *Tuner) Attributes() []attribute.KeyValue {
+	values := t.Values()
+	return []attribute.KeyValue{
+		attribute.Int("sprout.runtime.gomaxprocs", values.MaxProcs),
+		attribute.Int64("sprout.runtime.gomemlimit", values.MemoryLimit),
+		attribute.Int64("sprout.runtime.gogc", values.GOGC),
+		attribute.Int64("sprout.runtime.memory.available", values.AvailableMemory),
+	}
+}
+
+// registerMetrics registers metrics for the values that Sprout manages.
+// GOMAXPROCS, GOMEMLIMIT and GOGC are already reported by the runtime
+// instrumentation.
+func (t *Tuner) registerMetrics(meter metric.Meter) error {
+	_, err := meter.Int64ObservableGauge(
+		"sprout.runtime.memory.available",
+		metric.WithDescription("Memory available to the process as detected by the memory limit provider."),
+		metric.WithUnit("By"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			if available := t.Values().AvailableMemory; available > 0 {
+				o.Observe(available)
+			}
+			return nil
+		}),
+	)
+	return err
+}
@@ -1,45 +1,375 @@
 package runtime
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	goruntime "runtime"
 	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/KimMachineGun/automemlimit/memlimit"
+	"github.com/caarlos0/env/v11"
 	"go.uber.org/automaxprocs/maxprocs"
 	"go.uber.org/zap"
 )
 
-func Setup(logger *zap.Logger) {
-	// Setup GOMAXPROCS to be the number of CPUs available.
-	_, err := maxprocs.Set(maxprocs.Logger(func(s string, i ...any) {
-		s = strings.TrimPrefix(s, "maxprocs: ")
-		s = fmt.Sprintf(s, i...)
-		logger.Info(s)
-	}))
+// Config controls how the Go runtime is tuned for the environment the
+// service runs in.
+type Config struct {
+	// AutoMaxProcs sets GOMAXPROCS based on the CPU quota of the container.
+	AutoMaxProcs bool `env:"AUTOMAXPROCS" envDefault:"true"`
+
+	// MemoryLimitProvider is where the available memory is read from, one
+	// of cgroup, system, auto (cgroup with fallback to system) or none.
+	MemoryLimitProvider string `env:"MEMORY_LIMIT_PROVIDER" envDefault:"cgroup"`
+	// MemoryLimitRatio is the ratio of available memory to use as GOMEMLIMIT.
+	MemoryLimitRatio float64 `env:"MEMORY_LIMIT_RATIO" envDefault:"0.9"`
+	// MemoryLimit is a fixed GOMEMLIMIT, such as 512MiB, used instead of
+	// reading the available memory.
+	MemoryLimit string `env:"MEMORY_LIMIT"`
+
+	// GOGC sets the garbage collection target percentage, or off to disable
+	// the garbage collector.
+	GOGC string `env:"GOGC"`
+
+	// RefreshInterval is how often limits are re-evaluated, such as when a
+	// pod is resized in place. Set to 0 to disable.
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" envDefault:"1m"`
+}
+
+// Tuner applies the runtime configuration and keeps track of the values that
+// are in effect.
+type Tuner struct {
+	logger *zap.Logger
+	config Config
+
+	memoryProvider memlimit.Provider
+	// manageMemoryLimit is false if GOMEMLIMIT is set by the environment
+	manageMemoryLimit bool
+
+	mutex           sync.Mutex
+	availableMemory int64
+	// memoryMessage is the last message logged about the memory limit, so
+	// that refreshing only logs changes
+	memoryMessage string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Setup reads the runtime configuration from the environment and applies it.
+func Setup(logger *zap.Logger) (*Tuner, error) {
+	config, err := env.ParseAsWithOptions[Config](env.Options{
+		Prefix: "RUNTIME_",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tuner{
+		logger: logger,
+		config: config,
+	}
+
+	err = t.setupMemoryProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.setupGOGC()
 	if err != nil {
-		logger.Warn("Unable to set GOMAXPROCS", zap.Error(err))
+		return nil, err
 	}
 
-	setupMemoryLimit(logger)
+	if config.AutoMaxProcs {
+		// Setup GOMAXPROCS to be the number of CPUs available.
+		_, err = maxprocs.Set(maxprocs.Logger(func(s string, i ...any) {
+			s = strings.TrimPrefix(s, "maxprocs: ")
+			s = fmt.Sprintf(s, i...)
+			logger.Info(s)
+		}))
+		if err != nil {
+			logger.Warn("Unable to set GOMAXPROCS", zap.Error(err))
+		}
+	} else {
+		logger.Info("Automatic GOMAXPROCS disabled, leaving GOMAXPROCS=" + strconv.Itoa(goruntime.GOMAXPROCS(0)))
+	}
+
+	t.setupMemoryLimit()
+	return t, nil
 }
 
-func setupMemoryLimit(logger *zap.Logger) {
+func (t *Tuner) setupMemoryProvider() error {
 	if memLimit := debug.SetMemoryLimit(-1); memLimit != math.MaxInt64 {
-		logger.Info("Leaving GOMEMLIMIT=" + formatBytes(memLimit))
+		t.logger.Info("Leaving GOMEMLIMIT=" + formatBytes(memLimit))
+		return nil
+	}
+
+	if t.config.MemoryLimit != "" {
+		limit, err := parseBytes(t.config.MemoryLimit)
+		if err != nil {
+			return fmt.Errorf("invalid RUNTIME_MEMORY_LIMIT: %w", err)
+		}
+
+		t.memoryProvider = memlimit.Limit(uint64(limit)) //nolint:gosec
+		t.manageMemoryLimit = true
+		return nil
+	}
+
+	if t.config.MemoryLimitRatio <= 0 || t.config.MemoryLimitRatio > 1 {
+		return fmt.Errorf("invalid RUNTIME_MEMORY_LIMIT_RATIO %v, must be in the range (0, 1]", t.config.MemoryLimitRatio)
+	}
+
+	switch t.config.MemoryLimitProvider {
+	case "cgroup":
+		t.memoryProvider = memlimit.FromCgroup
+	case "system":
+		t.memoryProvider = memlimit.FromSystem
+	case "auto":
+		t.memoryProvider = memlimit.ApplyFallback(memlimit.FromCgroup, memlimit.FromSystem)
+	case "none":
+		t.logger.Info("Automatic GOMEMLIMIT disabled")
+		return nil
+	default:
+		return fmt.Errorf("invalid RUNTIME_MEMORY_LIMIT_PROVIDER %q, must be one of cgroup, system, auto or none", t.config.MemoryLimitProvider)
+	}
+
+	t.manageMemoryLimit = true
+	return nil
+}
+
+func (t *Tuner) setupGOGC() error {
+	switch t.config.GOGC {
+	case "":
+		return nil
+	case "off":
+		debug.SetGCPercent(-1)
+	default:
+		percent, err := strconv.Atoi(t.config.GOGC)
+		if err != nil {
+			return fmt.Errorf("invalid RUNTIME_GOGC %q, must be a number or off", t.config.GOGC)
+		}
+
+		debug.SetGCPercent(percent)
+	}
+
+	t.logger.Info("Setting GOGC=" + t.config.GOGC)
+	return nil
+}
+
+func (t *Tuner) setupMemoryLimit() {
+	if !t.manageMemoryLimit {
 		return
 	}
 
-	memLimit, err := memlimit.SetGoMemLimit(0.9)
-	if err != nil {
-		logger.Info("Unable to set GOMEMLIMIT: " + err.Error())
+	available, err := t.memoryProvider()
+	if errors.Is(err, memlimit.ErrNoLimit) {
+		t.mutex.Lock()
+		previous := t.availableMemory
+		t.availableMemory = 0
+		t.mutex.Unlock()
+
+		if previous == 0 {
+			t.logMemoryLimit("Memory is not limited, leaving GOMEMLIMIT unset")
+			return
+		}
+
+		// The limit of the container has been removed since it was applied
+		debug.SetMemoryLimit(math.MaxInt64)
+		t.logMemoryLimit("Memory is no longer limited, unsetting GOMEMLIMIT")
 		return
+	} else if err != nil {
+		t.logMemoryLimit("Unable to set GOMEMLIMIT: " + err.Error())
+		return
+	}
+
+	memLimit := capToInt64(available)
+	if t.config.MemoryLimit == "" {
+		memLimit = capToInt64(uint64(float64(available) * t.config.MemoryLimitRatio))
+	}
+
+	t.mutex.Lock()
+	t.availableMemory = capToInt64(available)
+	t.mutex.Unlock()
+
+	debug.SetMemoryLimit(memLimit)
+	t.logMemoryLimit("Setting GOMEMLIMIT=" + formatBytes(memLimit))
+}
+
+// logMemoryLimit logs the message unless it was the last message logged about
+// the memory limit, as the limit is re-evaluated periodically.
+func (t *Tuner) logMemoryLimit(message string) {
+	t.mutex.Lock()
+	changed := message != t.memoryMessage
+	t.memoryMessage = message
+	t.mutex.Unlock()
+
+	if changed {
+		t.logger.Info(message)
 	}
-	logger.Info("Setting GOMEMLIMIT=" + formatBytes(memLimit))
+}
+
+// refresh re-evaluates the CPU and memory limits, picking up changes to the
+// limits of the container.
+func (t *Tuner) refresh() {
+	if t.config.AutoMaxProcs {
+		previous := goruntime.GOMAXPROCS(0)
+		_, err := maxprocs.Set(maxprocs.Logger(func(string, ...any) {}))
+		if err != nil {
+			t.logger.Warn("Unable to set GOMAXPROCS", zap.Error(err))
+		} else if current := goruntime.GOMAXPROCS(0); current != previous {
+			t.logger.Info("CPU quota changed, updated GOMAXPROCS=" + strconv.Itoa(current))
+		}
+	}
+
+	t.setupMemoryLimit()
+}
+
+// Start starts the periodic re-evaluation of limits.
+func (t *Tuner) Start() {
+	if t.config.RefreshInterval <= 0 || t.stop != nil {
+		return
+	}
+
+	// The goroutine uses its own references as Stop clears t.stop
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	t.stop = stop
+	t.done = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(t.config.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic re-evaluation of limits.
+func (t *Tuner) Stop(ctx context.Context) error {
+	if t.stop == nil {
+		return nil
+	}
+
+	close(t.stop)
+	t.stop = nil
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Values contains the runtime settings that are in effect.
+type Values struct {
+	// MaxProcs is the current value of GOMAXPROCS.
+	MaxProcs int
+	// MemoryLimit is the current value of GOMEMLIMIT, 0 if not limited.
+	MemoryLimit int64
+	// AvailableMemory is the memory available to the process as detected by
+	// the memory limit provider, 0 if unknown.
+	AvailableMemory int64
+	// GOGC is the current garbage collection target percentage, negative if
+	// the garbage collector is disabled.
+	GOGC int64
+}
+
+// Values returns the runtime settings currently in effect.
+func (t *Tuner) Values() Values {
+	values := CurrentValues()
+
+	t.mutex.Lock()
+	values.AvailableMemory = t.availableMemory
+	t.mutex.Unlock()
+
+	return values
+}
+
+// CurrentValues reads the runtime settings currently in effect. The
+// available memory is not known without a Tuner and is left empty.
+func CurrentValues() Values {
+	samples := []metrics.Sample{
+		{Name: "/gc/gomemlimit:bytes"},
+		{Name: "/gc/gogc:percent"},
+	}
+	metrics.Read(samples)
+
+	memoryLimit := int64(samples[0].Value.Uint64()) //nolint:gosec
+	if memoryLimit == math.MaxInt64 {
+		memoryLimit = 0
+	}
+
+	// The percentage is stored as a signed value, -1 when disabled
+	gogc := int64(samples[1].Value.Uint64()) //nolint:gosec
+
+	return Values{
+		MaxProcs:    goruntime.GOMAXPROCS(0),
+		MemoryLimit: memoryLimit,
+		GOGC:        gogc,
+	}
+}
+
+func capToInt64(value uint64) int64 {
+	if value > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(value)
 }
 
 // formatBytes formats a byte count into MiB.
 func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.0fMiB", float64(bytes)/1024/1024)
 }
+
+// parseBytes parses a byte count in the same format as GOMEMLIMIT, such as
+// 512MiB or 2GiB.
+func parseBytes(value string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"TiB", 1 << 40},
+		{"GiB", 1 << 30},
+		{"MiB", 1 << 20},
+		{"KiB", 1 << 10},
+		{"B", 1},
+	}
+
+	multiplier := int64(1)
+	for _, unit := range units {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value = number
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if number <= 0 || number > math.MaxInt64/multiplier {
+		return 0, errors.New("value out of range")
+	}
+
+	return number * multiplier, nil
+}
@@ -0,0 +1,157 @@
+package runtime_test
+
+import (
+	"errors"
+	"math"
+	"runtime/debug"
+
+	"github.com/KimMachineGun/automemlimit/memlimit"
+	"github.com/aholstenson/sprout-go/internal/runtime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("Runtime", func() {
+	BeforeEach(func() {
+		memoryLimit := debug.SetMemoryLimit(math.MaxInt64)
+		gcPercent := debug.SetGCPercent(100)
+
+		DeferCleanup(func() {
+			debug.SetMemoryLimit(memoryLimit)
+			debug.SetGCPercent(gcPercent)
+		})
+	})
+
+	It("can set a fixed memory limit", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT", "256MiB")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tuner.Values().MemoryLimit).To(Equal(int64(256 * 1024 * 1024)))
+	})
+
+	It("memory limit can be disabled", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT_PROVIDER", "none")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tuner.Values().MemoryLimit).To(Equal(int64(0)))
+	})
+
+	It("existing memory limit is left as is", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT", "256MiB")
+		debug.SetMemoryLimit(128 * 1024 * 1024)
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tuner.Values().MemoryLimit).To(Equal(int64(128 * 1024 * 1024)))
+	})
+
+	It("memory limit is removed when the limit disappears", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT_PROVIDER", "none")
+		t.Setenv("RUNTIME_AUTOMAXPROCS", "false")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+
+		limit := uint64(512 * 1024 * 1024)
+		runtime.SetMemoryProvider(tuner, func() (uint64, error) {
+			if limit == 0 {
+				return 0, memlimit.ErrNoLimit
+			}
+			return limit, nil
+		})
+
+		runtime.Refresh(tuner)
+		Expect(tuner.Values().AvailableMemory).To(Equal(int64(512 * 1024 * 1024)))
+		Expect(tuner.Values().MemoryLimit).To(BeNumerically(">", 0))
+
+		limit = 0
+		runtime.Refresh(tuner)
+		Expect(tuner.Values().AvailableMemory).To(BeZero())
+		Expect(tuner.Values().MemoryLimit).To(BeZero())
+	})
+
+	It("memory limit is only logged when it changes", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT_PROVIDER", "none")
+		t.Setenv("RUNTIME_AUTOMAXPROCS", "false")
+
+		core, logs := observer.New(zap.InfoLevel)
+		tuner, err := runtime.Setup(zap.New(core))
+		Expect(err).ToNot(HaveOccurred())
+
+		var providerErr error
+		runtime.SetMemoryProvider(tuner, func() (uint64, error) {
+			return 0, providerErr
+		})
+
+		for _, providerErr = range []error{memlimit.ErrNoLimit, errors.New("cgroup unavailable")} {
+			logs.TakeAll()
+
+			runtime.Refresh(tuner)
+			Expect(logs.TakeAll()).To(HaveLen(1))
+
+			runtime.Refresh(tuner)
+			Expect(logs.TakeAll()).To(BeEmpty())
+		}
+	})
+
+	It("can set GOGC", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_GOGC", "50")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tuner.Values().GOGC).To(Equal(int64(50)))
+	})
+
+	It("can disable the garbage collector", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_GOGC", "off")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tuner.Values().GOGC).To(Equal(int64(-1)))
+	})
+
+	It("invalid configuration returns error", func() {
+		t := GinkgoT()
+
+		t.Setenv("RUNTIME_MEMORY_LIMIT", "lots")
+		_, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).To(HaveOccurred())
+
+		t.Setenv("RUNTIME_MEMORY_LIMIT", "")
+		t.Setenv("RUNTIME_MEMORY_LIMIT_RATIO", "1.5")
+		_, err = runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).To(HaveOccurred())
+
+		t.Setenv("RUNTIME_MEMORY_LIMIT_RATIO", "0.9")
+		t.Setenv("RUNTIME_MEMORY_LIMIT_PROVIDER", "magic")
+		_, err = runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reports values as resource attributes", func() {
+		t := GinkgoT()
+		t.Setenv("RUNTIME_MEMORY_LIMIT", "256MiB")
+
+		tuner, err := runtime.Setup(zaptest.NewLogger(t))
+		Expect(err).ToNot(HaveOccurred())
+
+		attributes := map[string]int64{}
+		for _, attr := range tuner.Attributes() {
+			attributes[string(attr.Key)] = attr.Value.AsInt64()
+		}
+		Expect(attributes).To(HaveKeyWithValue("sprout.runtime.gomemlimit", int64(256*1024*1024)))
+		Expect(attributes).To(HaveKey("sprout.runtime.gomaxprocs"))
+	})
+})
@@ -0,0 +1,264 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type metrics struct {
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newMetrics(meter metric.Meter, jobs []*job) (*metrics, error) {
+	runs, err := meter.Int64Counter(
+		"sprout.schedule.runs",
+		metric.WithDescription("Number of runs of scheduled jobs, including skipped runs."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(
+		"sprout.schedule.duration",
+		metric.WithDescription("Duration of runs of scheduled jobs."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = meter.Int64ObservableGauge(
+		"sprout.schedule.last_success",
+		metric.WithDescription("Unix time of the last successful run of scheduled jobs."),
+		metric.WithUnit("s"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			for _, j := range jobs {
+				if lastSuccess := j.Status().LastSuccess; lastSuccess != nil {
+					o.Observe(lastSuccess.Unix(), j.attributes)
+				}
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		runs:     runs,
+		duration: duration,
+	}, nil
+}
+
+// Status is the status of a scheduled job, as reported by the health server.
+type Status struct {
+	Name        string     `json:"name"`
+	Schedule    string     `json:"schedule"`
+	Running     bool       `json:"running"`
+	NextRun     *time.Time `json:"nextRun,omitempty"`
+	LastRun     *Run       `json:"lastRun,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+}
+
+// Run describes a single run of a scheduled job.
+type Run struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// job runs a scheduled job, skipping runs while a previous run is still in
+// progress.
+type job struct {
+	definition *Definition
+	logger     *zap.Logger
+	tracer     trace.Tracer
+	metrics    *metrics
+	attributes metric.MeasurementOption
+
+	mutex  sync.Mutex
+	status Status
+
+	cancel  context.CancelFunc
+	loop    sync.WaitGroup
+	running sync.WaitGroup
+}
+
+func newJob(definition *Definition, logger *zap.Logger, tracer trace.Tracer) *job {
+	return &job{
+		definition: definition,
+		logger:     logger.With(zap.String("job", definition.Name)),
+		tracer:     tracer,
+		attributes: metric.WithAttributes(attribute.String("job", definition.Name)),
+		status: Status{
+			Name:     definition.Name,
+			Schedule: definition.Spec,
+		},
+	}
+}
+
+// Status returns a copy of the current status of the job.
+func (j *job) Status() Status {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.status
+}
+
+// Start starts scheduling the job.
+func (j *job) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+
+	j.loop.Add(1)
+	go func() {
+		defer j.loop.Done()
+		j.schedule(ctx)
+	}()
+}
+
+// Stop stops scheduling and waits for a run in progress to finish.
+func (j *job) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+
+	j.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		j.loop.Wait()
+		j.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduled job %s did not stop: %w", j.definition.Name, ctx.Err())
+	}
+}
+
+func (j *job) schedule(ctx context.Context) {
+	for {
+		now := time.Now()
+		next := j.definition.schedule.Next(now)
+		if j.definition.Jitter > 0 {
+			next = next.Add(rand.N(j.definition.Jitter))
+		}
+
+		j.mutex.Lock()
+		j.status.NextRun = &next
+		j.mutex.Unlock()
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.trigger(ctx)
+	}
+}
+
+// trigger starts a run unless one is already in progress.
+func (j *job) trigger(ctx context.Context) {
+	j.mutex.Lock()
+	if j.status.Running {
+		j.mutex.Unlock()
+
+		j.logger.Warn("Previous run still in progress, skipping run")
+		j.metrics.runs.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("job", j.definition.Name),
+			attribute.String("result", "skipped"),
+		))
+		return
+	}
+	j.status.Running = true
+	j.mutex.Unlock()
+
+	j.running.Add(1)
+	go func() {
+		defer j.running.Done()
+		j.execute(ctx)
+	}()
+}
+
+func (j *job) execute(ctx context.Context) {
+	ctx, span := j.tracer.Start(
+		ctx,
+		"schedule "+j.definition.Name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("sprout.schedule.job", j.definition.Name)),
+	)
+	defer span.End()
+
+	defer func() {
+		if value := recover(); value != nil {
+			crash.Report(ctx, "scheduled job "+j.definition.Name, value, debug.Stack())
+		}
+	}()
+
+	if j.definition.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.definition.Timeout)
+		defer cancel()
+	}
+
+	logger := j.logger
+	if spanContext := span.SpanContext(); spanContext.IsValid() {
+		logger = logger.With(zap.String("traceID", spanContext.TraceID().String()))
+	}
+
+	logger.Debug("Running scheduled job")
+	start := time.Now()
+	err := j.definition.Run(ctx)
+	duration := time.Since(start)
+
+	run := &Run{
+		Start:    start,
+		Duration: duration,
+	}
+
+	result := "success"
+	if err != nil {
+		result = "error"
+		run.Error = err.Error()
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Scheduled job failed", zap.Error(err), zap.Duration("duration", duration))
+	} else {
+		logger.Info("Scheduled job finished", zap.Duration("duration", duration))
+	}
+
+	j.mutex.Lock()
+	j.status.Running = false
+	j.status.LastRun = run
+	if err == nil {
+		j.status.LastSuccess = &start
+	}
+	j.mutex.Unlock()
+
+	// The context of the run may be canceled, record with a fresh context
+	j.metrics.duration.Record(context.Background(), duration.Seconds(), j.attributes)
+	j.metrics.runs.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("job", j.definition.Name),
+		attribute.String("result", result),
+	))
+}
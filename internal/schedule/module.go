@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Module runs the jobs registered via Provide while the application is
+// running, and reports their status on /schedules of the health server.
+var Module = fx.Module(
+	"sprout:schedule",
+	fx.Provide(logging.Logger("schedule"), fx.Private),
+	fx.Invoke(register),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *zap.Logger
+	Routes         health.Routes
+	MeterProvider  metric.MeterProvider
+	TracerProvider trace.TracerProvider
+	Command        string `name:"service:command" optional:"true"`
+
+	Definitions []*Definition `group:"sprout:schedules"`
+}
+
+func register(p params) error {
+	if len(p.Definitions) == 0 {
+		return nil
+	}
+
+	if p.Command != "" {
+		p.Logger.Info("Not running scheduled jobs while running a command", zap.String("command", p.Command))
+		return nil
+	}
+
+	tracer := p.TracerProvider.Tracer("sprout/schedule")
+
+	names := make(map[string]struct{}, len(p.Definitions))
+	jobs := make([]*job, 0, len(p.Definitions))
+	for _, definition := range p.Definitions {
+		if _, exists := names[definition.Name]; exists {
+			return fmt.Errorf("scheduled job %s registered more than once", definition.Name)
+		}
+		names[definition.Name] = struct{}{}
+
+		jobs = append(jobs, newJob(definition, p.Logger, tracer))
+	}
+
+	metrics, err := newMetrics(p.MeterProvider.Meter("sprout/schedule"), jobs)
+	if err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		j.metrics = metrics
+
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				j.Start()
+				return nil
+			},
+			OnStop: j.Stop,
+		})
+	}
+
+	p.Routes.AddRoute("GET /schedules", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := make([]Status, 0, len(jobs))
+		for _, j := range jobs {
+			statuses = append(statuses, j.Status())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(statuses)
+	}))
+	return nil
+}
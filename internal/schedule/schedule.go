@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/constructor"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+)
+
+// Func is the function run by a scheduled job.
+type Func func(ctx context.Context) error
+
+// Definition describes a scheduled job.
+type Definition struct {
+	Name string
+	Spec string
+	Run  Func
+
+	// Jitter is the maximum random delay added before every run, spreading
+	// out load when many replicas run the same job
+	Jitter time.Duration
+	// Timeout is the maximum duration of a single run, 0 means no limit
+	Timeout time.Duration
+
+	schedule cron.Schedule
+}
+
+// Option configures a scheduled job.
+type Option func(*Definition)
+
+// WithJitter adds a random delay of up to jitter before every run.
+func WithJitter(jitter time.Duration) Option {
+	return func(d *Definition) {
+		d.Jitter = jitter
+	}
+}
+
+// WithTimeout limits the duration of a single run.
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Definition) {
+		d.Timeout = timeout
+	}
+}
+
+// Parse parses a cron expression. Both standard five field expressions and
+// descriptors such as @hourly and @every 5m are supported.
+func Parse(spec string) (cron.Schedule, error) {
+	return cron.ParseStandard(spec)
+}
+
+// Provide creates a constructor for use with fx.Provide that registers a
+// scheduled job. fn is either the function to run, or a constructor that
+// takes dependencies and returns the function to run, optionally with an
+// error.
+func Provide(name string, spec string, fn any, options ...Option) any {
+	schedule, err := Parse(spec)
+
+	var c any
+	if err == nil {
+		c, err = constructor.Wrap(fn, func(run Func) *Definition {
+			d := &Definition{
+				Name:     name,
+				Spec:     spec,
+				Run:      run,
+				schedule: schedule,
+			}
+
+			for _, option := range options {
+				option(d)
+			}
+
+			return d
+		})
+	}
+
+	if err != nil {
+		// Report the error when the application is created
+		c = func() (*Definition, error) {
+			return nil, fmt.Errorf("invalid scheduled job %s: %w", name, err)
+		}
+	}
+
+	return fx.Annotate(c, fx.ResultTags(`group:"sprout:schedules"`))
+}
@@ -0,0 +1,16 @@
+package schedule_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSchedule(t *testing.T) {
+	// Avoid conflicts with other packages testing the health server
+	t.Setenv("HEALTH_SERVER_PORT", "8093")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schedule Suite")
+}
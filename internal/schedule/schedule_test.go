@@ -0,0 +1,123 @@
+package schedule_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/schedule"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Schedule", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+	})
+
+	AfterEach(func() {
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	newApp := func(options ...fx.Option) *fxtest.App {
+		return fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			fx.Provide(func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}),
+			schedule.Module,
+			fx.Options(options...),
+		)
+	}
+
+	statuses := func() []schedule.Status {
+		res, err := http.Get("http://localhost:8093/schedules")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+
+		var statuses []schedule.Status
+		Expect(json.NewDecoder(res.Body).Decode(&statuses)).To(Succeed())
+		return statuses
+	}
+
+	It("runs jobs on the schedule with a span per run", func() {
+		var runs atomic.Int32
+
+		app := newApp(fx.Provide(schedule.Provide("test", "@every 1s", func(ctx context.Context) error {
+			runs.Add(1)
+			if runs.Load() == 1 {
+				return errors.New("failed")
+			}
+			return nil
+		})))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(runs.Load).WithTimeout(3 * time.Second).Should(BeNumerically(">=", 2))
+
+		spans := recorder.Ended()
+		Expect(spans).ToNot(BeEmpty())
+		Expect(spans[0].Name()).To(Equal("schedule test"))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+
+		s := statuses()
+		Expect(s).To(HaveLen(1))
+		Expect(s[0].Name).To(Equal("test"))
+		Expect(s[0].Schedule).To(Equal("@every 1s"))
+		Expect(s[0].NextRun).ToNot(BeNil())
+		Expect(s[0].LastRun).ToNot(BeNil())
+		Expect(s[0].LastSuccess).ToNot(BeNil())
+	})
+
+	It("skips runs while a previous run is in progress and applies the timeout", func() {
+		var runs atomic.Int32
+		var timedOut atomic.Bool
+
+		app := newApp(fx.Provide(schedule.Provide("slow", "@every 1s", func(ctx context.Context) error {
+			runs.Add(1)
+			select {
+			case <-ctx.Done():
+				timedOut.Store(true)
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		}, schedule.WithTimeout(1500*time.Millisecond))))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(timedOut.Load).WithTimeout(4 * time.Second).Should(BeTrue())
+		Expect(runs.Load()).To(Equal(int32(1)))
+		Expect(statuses()[0].LastRun.Error).To(ContainSubstring("deadline exceeded"))
+	})
+
+	It("fails for invalid schedules", func() {
+		app := fx.New(
+			fx.NopLogger,
+			fx.Provide(schedule.Provide("test", "not a schedule", func(ctx context.Context) error {
+				return nil
+			})),
+			fx.Invoke(fx.Annotate(func([]*schedule.Definition) {}, fx.ParamTags(`group:"sprout:schedules"`))),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("invalid scheduled job test")))
+	})
+})
@@ -9,6 +9,9 @@ type ServiceInfo struct {
 	Name    string `name:"service:name"`
 	Version string `name:"service:version"`
 
+	// Command is the command being run, empty when serving
+	Command string `name:"service:command"`
+
 	Development bool `name:"env:development"`
 	Testing     bool `name:"env:testing"`
 }
@@ -0,0 +1,27 @@
+package shutdown
+
+import (
+	"go.uber.org/fx"
+)
+
+// Module shuts down the registered components when the application stops.
+// It should be included before other modules, so that it is stopped last.
+//
+// If managed is true the application is stopped by a caller that shuts down
+// the coordinator itself once it has logged the result, and stopping the
+// application does not.
+func Module(coordinator *Coordinator, managed bool) fx.Option {
+	return fx.Module(
+		"sprout:shutdown",
+		fx.Supply(coordinator),
+		fx.Invoke(func(lifecycle fx.Lifecycle) {
+			if managed {
+				return
+			}
+
+			lifecycle.Append(fx.Hook{
+				OnStop: coordinator.Shutdown,
+			})
+		}),
+	)
+}
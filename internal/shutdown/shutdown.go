@@ -0,0 +1,121 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config controls how long shutdown may take.
+type Config struct {
+	// FlushTimeout is the maximum time to wait for components to flush and
+	// shut down.
+	FlushTimeout time.Duration `env:"FLUSH_TIMEOUT" envDefault:"5s"`
+}
+
+// Coordinator shuts down components that need to be flushed before the
+// process exits, such as the telemetry providers. Components are shut down
+// on normal stop of the application, on fatal logs and when the application
+// fails to start.
+type Coordinator struct {
+	config Config
+
+	mutex sync.Mutex
+	hooks []hook
+	// running is true while registered functions are being called
+	running bool
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New reads the shutdown configuration from the environment and creates a
+// coordinator.
+func New() (*Coordinator, error) {
+	config, err := env.ParseAsWithOptions[Config](env.Options{
+		Prefix: "SHUTDOWN_",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Coordinator{
+		config: config,
+	}, nil
+}
+
+// Register registers a function that is called on shutdown. Functions are
+// called in the reverse order they were registered in.
+func (c *Coordinator) Register(name string, fn func(ctx context.Context) error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.hooks = append(c.hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown calls all registered functions, bounded by both the context and
+// the flush timeout. Functions are only called once, so a later shutdown
+// only calls functions registered after the previous one, such as those of
+// another application. Shutting down while a shutdown is in progress, such
+// as on a fatal log in a registered function, returns directly.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.mutex.Lock()
+	if c.running {
+		c.mutex.Unlock()
+		return nil
+	}
+
+	hooks := c.hooks
+	c.hooks = nil
+	c.running = true
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.running = false
+		c.mutex.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.FlushTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		err := h.fn(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Flush shuts down all registered components, for use before the process
+// exits.
+func (c *Coordinator) Flush() error {
+	return c.Shutdown(context.Background())
+}
+
+// FatalHook returns a hook for zap that flushes all registered components
+// before exiting on fatal logs.
+func (c *Coordinator) FatalHook() zapcore.CheckWriteHook {
+	return fatalHook{coordinator: c}
+}
+
+type fatalHook struct {
+	coordinator *Coordinator
+}
+
+func (h fatalHook) OnWrite(entry *zapcore.CheckedEntry, fields []zapcore.Field) {
+	_ = h.coordinator.Flush()
+	os.Exit(1)
+}
@@ -0,0 +1,161 @@
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/shutdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+var _ = Describe("Coordinator", func() {
+	var coordinator *shutdown.Coordinator
+
+	BeforeEach(func() {
+		var err error
+		coordinator, err = shutdown.New()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("shuts down in reverse order of registration", func() {
+		var order []string
+		for _, name := range []string{"logging", "tracing", "metrics"} {
+			coordinator.Register(name, func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+
+		Expect(coordinator.Shutdown(context.Background())).To(Succeed())
+		Expect(order).To(Equal([]string{"metrics", "tracing", "logging"}))
+	})
+
+	It("only shuts down once", func() {
+		calls := 0
+		coordinator.Register("test", func(ctx context.Context) error {
+			calls++
+			return errors.New("failed")
+		})
+
+		err := coordinator.Flush()
+		Expect(err).To(MatchError("test: failed"))
+
+		Expect(coordinator.Shutdown(context.Background())).To(Succeed())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("shuts down functions registered after a shutdown on the next one", func() {
+		Expect(coordinator.Flush()).To(Succeed())
+
+		called := false
+		coordinator.Register("late", func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		Expect(called).To(BeFalse())
+
+		Expect(coordinator.Flush()).To(Succeed())
+		Expect(called).To(BeTrue())
+	})
+
+	It("returns directly when flushed during shutdown", func() {
+		calls := 0
+		coordinator.Register("test", func(ctx context.Context) error {
+			calls++
+			// As done by the fatal hook on fatal logs during shutdown
+			return coordinator.Flush()
+		})
+
+		done := make(chan error)
+		go func() {
+			done <- coordinator.Flush()
+		}()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("bounds shutdown by the flush timeout", func() {
+		GinkgoT().Setenv("SHUTDOWN_FLUSH_TIMEOUT", "50ms")
+		coordinator, err := shutdown.New()
+		Expect(err).ToNot(HaveOccurred())
+
+		coordinator.Register("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		start := time.Now()
+		err = coordinator.Flush()
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("shuts down after other components when the application stops", func() {
+		var order []string
+		coordinator.Register("telemetry", func(ctx context.Context) error {
+			order = append(order, "telemetry")
+			return nil
+		})
+
+		app := fxtest.New(
+			GinkgoT(),
+			shutdown.Module(coordinator, false),
+			fx.Invoke(func(lifecycle fx.Lifecycle) {
+				lifecycle.Append(fx.Hook{
+					OnStop: func(ctx context.Context) error {
+						order = append(order, "component")
+						return nil
+					},
+				})
+			}),
+		)
+		app.RequireStart()
+		app.RequireStop()
+
+		Expect(order).To(Equal([]string{"component", "telemetry"}))
+	})
+
+	It("shuts down every application using the coordinator", func() {
+		var stopped []string
+		for _, name := range []string{"first", "second"} {
+			app := fxtest.New(
+				GinkgoT(),
+				shutdown.Module(coordinator, false),
+				fx.Invoke(func(coordinator *shutdown.Coordinator) {
+					coordinator.Register(name, func(ctx context.Context) error {
+						stopped = append(stopped, name)
+						return nil
+					})
+				}),
+			)
+			app.RequireStart()
+			Expect(stopped).ToNot(ContainElement(name))
+			app.RequireStop()
+		}
+
+		Expect(stopped).To(Equal([]string{"first", "second"}))
+	})
+
+	It("does not shut down when the application is managed", func() {
+		called := false
+		coordinator.Register("telemetry", func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+
+		app := fxtest.New(
+			GinkgoT(),
+			shutdown.Module(coordinator, true),
+		)
+		app.RequireStart()
+		app.RequireStop()
+		Expect(called).To(BeFalse())
+
+		Expect(coordinator.Flush()).To(Succeed())
+		Expect(called).To(BeTrue())
+	})
+})
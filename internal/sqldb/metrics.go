@@ -0,0 +1,88 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	stateInUse = metric.WithAttributes(attribute.String("state", "in_use"))
+	stateIdle  = metric.WithAttributes(attribute.String("state", "idle"))
+
+	reasonMaxIdle     = metric.WithAttributes(attribute.String("reason", "max_idle"))
+	reasonMaxIdleTime = metric.WithAttributes(attribute.String("reason", "max_idle_time"))
+	reasonMaxLifetime = metric.WithAttributes(attribute.String("reason", "max_lifetime"))
+)
+
+// registerMetrics exports the statistics of the connection pool, which are
+// read once per collection.
+func registerMetrics(meter metric.Meter, db *sql.DB) (metric.Registration, error) {
+	connections, err := meter.Int64ObservableGauge(
+		"sprout.sql.connections",
+		metric.WithDescription("Number of connections in the pool, by state."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConnections, err := meter.Int64ObservableGauge(
+		"sprout.sql.connections.max",
+		metric.WithDescription("Maximum number of open connections, 0 means no limit."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	waits, err := meter.Int64ObservableCounter(
+		"sprout.sql.waits",
+		metric.WithDescription("Number of times a connection was waited for."),
+		metric.WithUnit("{wait}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	waitDuration, err := meter.Float64ObservableCounter(
+		"sprout.sql.wait_duration",
+		metric.WithDescription("Total time spent waiting for connections."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := meter.Int64ObservableCounter(
+		"sprout.sql.connections.closed",
+		metric.WithDescription("Number of connections closed by the pool, by reason."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			stats := db.Stats()
+
+			o.ObserveInt64(connections, int64(stats.InUse), stateInUse)
+			o.ObserveInt64(connections, int64(stats.Idle), stateIdle)
+			o.ObserveInt64(maxConnections, int64(stats.MaxOpenConnections))
+			o.ObserveInt64(waits, stats.WaitCount)
+			o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds())
+			o.ObserveInt64(closed, stats.MaxIdleClosed, reasonMaxIdle)
+			o.ObserveInt64(closed, stats.MaxIdleTimeClosed, reasonMaxIdleTime)
+			o.ObserveInt64(closed, stats.MaxLifetimeClosed, reasonMaxLifetime)
+			return nil
+		},
+		connections,
+		maxConnections,
+		waits,
+		waitDuration,
+		closed,
+	)
+}
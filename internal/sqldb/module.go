@@ -0,0 +1,148 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// DSN is the data source name passed to the driver, it is removed from
+	// the environment once read as it usually contains credentials
+	DSN string `env:"DSN,required,unset"`
+	// ServerAddress is the host of the database, added to spans and metrics.
+	// It is not derived from DSN as that may contain credentials
+	ServerAddress string `env:"SERVER_ADDRESS"`
+	// ServerPort is the port of the database, added to spans and metrics
+	ServerPort int `env:"SERVER_PORT"`
+
+	// MaxOpenConns is the maximum number of open connections, 0 means no
+	// limit
+	MaxOpenConns int `env:"MAX_OPEN_CONNS" envDefault:"10"`
+	// MaxIdleConns is the maximum number of idle connections kept in the
+	// pool
+	MaxIdleConns int `env:"MAX_IDLE_CONNS" envDefault:"5"`
+	// ConnMaxLifetime is the maximum time a connection is reused, 0 means no
+	// limit
+	ConnMaxLifetime time.Duration `env:"CONN_MAX_LIFETIME" envDefault:"30m"`
+	// ConnMaxIdleTime is the maximum time a connection may be idle, 0 means
+	// no limit
+	ConnMaxIdleTime time.Duration `env:"CONN_MAX_IDLE_TIME" envDefault:"5m"`
+
+	// PingTimeout is the maximum duration of the ping used as readiness
+	// check
+	PingTimeout time.Duration `env:"PING_TIMEOUT" envDefault:"5s"`
+	// TraceStatements controls if statements are included in spans
+	TraceStatements bool `env:"TRACE_STATEMENTS" envDefault:"true"`
+}
+
+// Module opens a connection pool using the named driver, which must have
+// been registered with database/sql. The pool is traced, measured, checked
+// for readiness and closed when the application stops.
+func Module(driverName string) fx.Option {
+	return fx.Module(
+		"sprout:sql",
+		fx.Provide(config.Config("SQL", Config{}), fx.Private),
+		fx.Provide(logging.Logger("sql"), fx.Private),
+		// The driver is used to determine the dialect of migrations
+		fx.Provide(fx.Annotated{
+			Name: "sql:driver",
+			Target: func() string {
+				return driverName
+			},
+		}),
+		fx.Provide(func(p params) (*sql.DB, error) {
+			return open(driverName, p)
+		}),
+	)
+}
+
+type params struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *zap.Logger
+	Config         Config
+	Checks         health.Checks
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+func open(driverName string, p params) (*sql.DB, error) {
+	db, err := otelsql.Open(
+		driverName,
+		p.Config.DSN,
+		otelsql.WithTracerProvider(p.TracerProvider),
+		otelsql.WithMeterProvider(p.MeterProvider),
+		otelsql.WithAttributes(serverAttributes(p.Config)...),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			DisableQuery:         !p.Config.TraceStatements,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(p.Config.MaxOpenConns)
+	db.SetMaxIdleConns(p.Config.MaxIdleConns)
+	db.SetConnMaxLifetime(p.Config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(p.Config.ConnMaxIdleTime)
+
+	registration, err := registerMetrics(p.MeterProvider.Meter("sprout/sql"), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	p.Checks.AddReadinessCheck(health.Check{
+		Name:   "sql",
+		Groups: []string{health.GroupExternal},
+		Check: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, p.Config.PingTimeout)
+			defer cancel()
+			return db.PingContext(ctx)
+		},
+	})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			p.Logger.Info("Closing database connections")
+			_ = registration.Unregister()
+			return db.Close()
+		},
+	})
+
+	p.Logger.Info(
+		"Opened database connection pool",
+		zap.String("driver", driverName),
+		zap.Int("maxOpenConns", p.Config.MaxOpenConns),
+		zap.Int("maxIdleConns", p.Config.MaxIdleConns),
+	)
+	return db, nil
+}
+
+// serverAttributes returns the attributes identifying the database server.
+func serverAttributes(c Config) []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	if c.ServerAddress != "" {
+		attributes = append(attributes, semconv.ServerAddress(c.ServerAddress))
+	}
+	if c.ServerPort > 0 {
+		attributes = append(attributes, semconv.ServerPort(c.ServerPort))
+	}
+	return attributes
+}
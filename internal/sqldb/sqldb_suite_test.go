@@ -0,0 +1,16 @@
+package sqldb_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSQL(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8096")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SQL Suite")
+}
@@ -0,0 +1,188 @@
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/sqldb"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+	_ "modernc.org/sqlite"
+)
+
+var _ = Describe("SQL", func() {
+	var recorder *tracetest.SpanRecorder
+	var reader *sdkmetric.ManualReader
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		reader = sdkmetric.NewManualReader()
+
+		GinkgoT().Setenv("SQL_DSN", filepath.Join(GinkgoT().TempDir(), "test.db"))
+	})
+
+	options := func(options ...fx.Option) []fx.Option {
+		return append([]fx.Option{
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)) }),
+			fx.Provide(func() trace.TracerProvider {
+				return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			}),
+			sqldb.Module("sqlite"),
+		}, options...)
+	}
+
+	It("provides a traced connection pool", func() {
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, err := db.Exec("CREATE TABLE users (name TEXT)")
+		Expect(err).ToNot(HaveOccurred())
+
+		var count int
+		err = db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(0))
+
+		var names []string
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(ContainElements("sql.conn.exec", "sql.conn.query"))
+	})
+
+	It("does not add the DSN to spans", func() {
+		// Credentials in the DSN must never end up in telemetry. SQLite
+		// uses the DSN as a file name, so a relative file shaped like a
+		// key/value DSN is used
+		wd, err := os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Chdir(GinkgoT().TempDir())).To(Succeed())
+		DeferCleanup(os.Chdir, wd)
+		GinkgoT().Setenv("SQL_DSN", "host=db.internal user=app password=s3cret")
+		GinkgoT().Setenv("SQL_SERVER_ADDRESS", "db.internal")
+		GinkgoT().Setenv("SQL_SERVER_PORT", "5432")
+
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, err = db.Exec("CREATE TABLE users (name TEXT)")
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := recorder.Ended()
+		Expect(spans).ToNot(BeEmpty())
+		for _, span := range spans {
+			for _, attr := range span.Attributes() {
+				Expect(attr.Value.Emit()).ToNot(ContainSubstring("s3cret"), "attribute %s", attr.Key)
+			}
+			Expect(span.Attributes()).To(ContainElements(
+				attribute.String("server.address", "db.internal"),
+				attribute.Int("server.port", 5432),
+			))
+		}
+	})
+
+	It("removes the DSN from the environment", func() {
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		_, ok := os.LookupEnv("SQL_DSN")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("configures the pool from the environment", func() {
+		GinkgoT().Setenv("SQL_MAX_OPEN_CONNS", "3")
+
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(db.Stats().MaxOpenConnections).To(Equal(3))
+	})
+
+	It("exports pool statistics as metrics", func() {
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(db.Ping()).To(Succeed())
+
+		var data metricdata.ResourceMetrics
+		Expect(reader.Collect(context.Background(), &data)).To(Succeed())
+
+		names := map[string]bool{}
+		for _, scope := range data.ScopeMetrics {
+			if scope.Scope.Name != "sprout/sql" {
+				continue
+			}
+			for _, m := range scope.Metrics {
+				names[m.Name] = true
+			}
+		}
+		Expect(names).To(HaveKey("sprout.sql.connections"))
+		Expect(names).To(HaveKey("sprout.sql.connections.max"))
+		Expect(names).To(HaveKey("sprout.sql.waits"))
+		Expect(names).To(HaveKey("sprout.sql.wait_duration"))
+		Expect(names).To(HaveKey("sprout.sql.connections.closed"))
+	})
+
+	It("reports readiness by pinging the database", func() {
+		var status health.Status
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&status))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(status.Ready(context.Background())).To(BeTrue())
+	})
+
+	It("is not ready if the database can not be reached", func() {
+		GinkgoT().Setenv("SQL_DSN", filepath.Join(GinkgoT().TempDir(), "missing", "test.db"))
+
+		var status health.Status
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&status), fx.Invoke(func(*sql.DB) {}))...)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(status.Ready(context.Background())).To(BeFalse())
+	})
+
+	It("closes the pool when stopped", func() {
+		var db *sql.DB
+		app := fxtest.New(GinkgoT(), options(fx.Populate(&db))...)
+		app.RequireStart()
+		app.RequireStop()
+
+		Expect(db.Ping()).To(MatchError(ContainSubstring("database is closed")))
+	})
+
+	It("requires a DSN", func() {
+		GinkgoT().Setenv("SQL_DSN", "")
+		Expect(os.Unsetenv("SQL_DSN")).To(Succeed())
+
+		app := fx.New(append(options(fx.Invoke(func(*sql.DB) {})), fx.NopLogger)...)
+		Expect(app.Err()).To(MatchError(ContainSubstring("failed to load configuration")))
+	})
+})
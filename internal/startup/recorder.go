@@ -0,0 +1,229 @@
+// Package startup records how long it takes to start an application, based
+// on the events emitted by Fx.
+package startup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// Slowest is the number of slowest steps included in the logged summary
+	Slowest int `env:"SLOWEST" envDefault:"5"`
+	// File is a path the report is written to as JSON once started
+	File string `env:"FILE"`
+	// Trace controls if a trace with a span per step is created
+	Trace bool `env:"TRACE" envDefault:"true"`
+}
+
+// Recorder is an fxevent.Logger that records the steps taken while starting
+// the application, passing all events on to another logger.
+type Recorder struct {
+	next   fxevent.Logger
+	logger *zap.Logger
+	config Config
+
+	mutex    sync.Mutex
+	report   Report
+	invoking time.Time
+
+	rootCtx  context.Context
+	rootSpan trace.Span
+	hookSpan trace.Span
+}
+
+// NewRecorder creates a recorder configured via environment variables
+// prefixed with STARTUP_REPORT_. The summary is logged to logger and every
+// event is passed on to next.
+func NewRecorder(logger *zap.Logger, next fxevent.Logger) (*Recorder, error) {
+	config, err := env.ParseAsWithOptions[Config](env.Options{
+		Prefix: "STARTUP_REPORT_",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		next:   next,
+		logger: logger,
+		config: config,
+		report: Report{
+			Began: time.Now(),
+		},
+	}, nil
+}
+
+// Report returns the steps recorded so far.
+func (r *Recorder) Report() Report {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	report := r.report
+	report.Steps = append([]Step(nil), r.report.Steps...)
+	return report
+}
+
+func (r *Recorder) LogEvent(event fxevent.Event) {
+	r.next.LogEvent(event)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	switch e := event.(type) {
+	case *fxevent.Run:
+		r.add(Step{
+			Kind:     e.Kind,
+			Name:     e.Name,
+			Module:   e.ModuleName,
+			Start:    now.Add(-e.Runtime),
+			Duration: e.Runtime,
+		}, e.Err)
+	case *fxevent.Invoking:
+		r.invoking = now
+	case *fxevent.Invoked:
+		r.add(Step{
+			Kind:     KindInvoke,
+			Name:     e.FunctionName,
+			Module:   e.ModuleName,
+			Start:    r.invoking,
+			Duration: now.Sub(r.invoking),
+		}, e.Err)
+	case *fxevent.OnStartExecuting:
+		if ctx := r.traceContext(); ctx != nil {
+			_, r.hookSpan = r.tracer().Start(
+				ctx,
+				"start "+e.FunctionName,
+				trace.WithTimestamp(now),
+				trace.WithAttributes(attribute.String("fx.caller", e.CallerName)),
+			)
+		}
+	case *fxevent.OnStartExecuted:
+		r.add(Step{
+			Kind:     KindStart,
+			Name:     e.FunctionName,
+			Start:    now.Add(-e.Runtime),
+			Duration: e.Runtime,
+		}, e.Err)
+
+		if r.hookSpan != nil {
+			endSpan(r.hookSpan, now, e.Err)
+			r.hookSpan = nil
+		}
+	case *fxevent.Started:
+		r.report.Duration = now.Sub(r.report.Began)
+		if e.Err != nil {
+			r.report.Error = e.Err.Error()
+		}
+
+		if r.traceContext() != nil {
+			endSpan(r.rootSpan, now, e.Err)
+		}
+
+		r.logSummary()
+		r.writeFile()
+	}
+}
+
+func (r *Recorder) add(step Step, err error) {
+	if err != nil {
+		step.Error = err.Error()
+	}
+	r.report.Steps = append(r.report.Steps, step)
+}
+
+func (r *Recorder) tracer() trace.Tracer {
+	return otel.Tracer("sprout/startup")
+}
+
+// traceContext returns the context of the startup trace, creating it on
+// first use. The trace is created when the application starts, as tracing
+// is set up by a constructor, and spans for the invokes that have already
+// run are added to it afterwards.
+func (r *Recorder) traceContext() context.Context {
+	if !r.config.Trace {
+		return nil
+	}
+
+	if r.rootCtx != nil {
+		return r.rootCtx
+	}
+
+	r.rootCtx, r.rootSpan = r.tracer().Start(
+		context.Background(),
+		"startup",
+		trace.WithTimestamp(r.report.Began),
+		trace.WithNewRoot(),
+	)
+
+	for _, step := range r.report.Steps {
+		if step.Kind != KindInvoke {
+			continue
+		}
+
+		_, span := r.tracer().Start(
+			r.rootCtx,
+			"invoke "+step.Name,
+			trace.WithTimestamp(step.Start),
+			trace.WithAttributes(attribute.String("fx.module", step.Module)),
+		)
+		if step.Error != "" {
+			span.SetStatus(codes.Error, step.Error)
+		}
+		span.End(trace.WithTimestamp(step.Start.Add(step.Duration)))
+	}
+
+	return r.rootCtx
+}
+
+func endSpan(span trace.Span, now time.Time, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(now))
+}
+
+func (r *Recorder) logSummary() {
+	fields := []zap.Field{
+		zap.Duration("duration", r.report.Duration),
+		zap.Duration("provide", r.report.Total(KindProvide)+r.report.Total(KindDecorate)),
+		zap.Duration("start", r.report.Total(KindStart)),
+	}
+	if r.config.Slowest > 0 {
+		fields = append(fields, zap.Objects("slowest", r.report.Slowest(r.config.Slowest)))
+	}
+
+	r.logger.Info("Startup report", fields...)
+}
+
+func (r *Recorder) writeFile() {
+	if r.config.File == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(r.report, "", "  ")
+	if err != nil {
+		r.logger.Warn("Could not encode startup report", zap.Error(err))
+		return
+	}
+
+	err = os.WriteFile(r.config.File, data, 0o644)
+	if err != nil {
+		r.logger.Warn("Could not write startup report", zap.String("file", r.config.File), zap.Error(err))
+		return
+	}
+
+	r.logger.Info("Wrote startup report", zap.String("file", r.config.File))
+}
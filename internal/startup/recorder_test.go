@@ -0,0 +1,169 @@
+package startup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/startup"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap/zaptest"
+)
+
+type slowService struct{}
+
+func newSlowService() *slowService {
+	time.Sleep(20 * time.Millisecond)
+	return &slowService{}
+}
+
+var _ = Describe("Recorder", func() {
+	var spans *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		spans = tracetest.NewSpanRecorder()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		DeferCleanup(func() {
+			otel.SetTracerProvider(previous)
+		})
+	})
+
+	newRecorder := func() *startup.Recorder {
+		recorder, err := startup.NewRecorder(zaptest.NewLogger(GinkgoT()), fxevent.NopLogger)
+		Expect(err).ToNot(HaveOccurred())
+		return recorder
+	}
+
+	start := func(recorder *startup.Recorder, options ...fx.Option) error {
+		app := fx.New(
+			fx.WithLogger(func() fxevent.Logger { return recorder }),
+			fx.Module("slow",
+				fx.Provide(newSlowService),
+				fx.Invoke(func(lifecycle fx.Lifecycle, _ *slowService) {
+					lifecycle.Append(fx.StartHook(func() {
+						time.Sleep(10 * time.Millisecond)
+					}))
+				}),
+			),
+			fx.Options(options...),
+		)
+		Expect(app.Err()).ToNot(HaveOccurred())
+
+		err := app.Start(context.Background())
+		DeferCleanup(func() {
+			_ = app.Stop(context.Background())
+		})
+		return err
+	}
+
+	It("records constructors, invokes and hooks", func() {
+		recorder := newRecorder()
+		Expect(start(recorder)).To(Succeed())
+
+		report := recorder.Report()
+		Expect(report.Duration).To(BeNumerically(">=", 30*time.Millisecond))
+		Expect(report.Error).To(BeEmpty())
+
+		Expect(report.Steps).To(ContainElement(And(
+			HaveField("Kind", startup.KindProvide),
+			HaveField("Name", ContainSubstring("newSlowService")),
+			HaveField("Module", "slow"),
+			HaveField("Duration", BeNumerically(">=", 20*time.Millisecond)),
+		)))
+		Expect(report.Steps).To(ContainElement(And(
+			HaveField("Kind", startup.KindInvoke),
+			HaveField("Module", "slow"),
+			HaveField("Duration", BeNumerically(">=", 20*time.Millisecond)),
+		)))
+		Expect(report.Steps).To(ContainElement(And(
+			HaveField("Kind", startup.KindStart),
+			HaveField("Duration", BeNumerically(">=", 10*time.Millisecond)),
+		)))
+
+		slowest := report.Slowest(1)
+		Expect(slowest).To(HaveLen(1))
+		Expect(slowest[0].Name).To(ContainSubstring("newSlowService"))
+	})
+
+	It("creates a startup trace", func() {
+		recorder := newRecorder()
+		Expect(start(recorder)).To(Succeed())
+
+		var names []string
+		for _, span := range spans.Ended() {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(ContainElement("startup"))
+		Expect(names).To(ContainElement(HavePrefix("invoke ")))
+		Expect(names).To(ContainElement(HavePrefix("start ")))
+
+		var root sdktrace.ReadOnlySpan
+		for _, span := range spans.Ended() {
+			if span.Name() == "startup" {
+				root = span
+			}
+		}
+		for _, span := range spans.Ended() {
+			if span.Name() != "startup" {
+				Expect(span.Parent().SpanID()).To(Equal(root.SpanContext().SpanID()))
+			}
+		}
+	})
+
+	It("does not create a trace if disabled", func() {
+		GinkgoT().Setenv("STARTUP_REPORT_TRACE", "false")
+
+		recorder := newRecorder()
+		Expect(start(recorder)).To(Succeed())
+		Expect(spans.Ended()).To(BeEmpty())
+	})
+
+	It("records errors from hooks", func() {
+		recorder := newRecorder()
+		err := start(recorder, fx.Invoke(func(lifecycle fx.Lifecycle) {
+			lifecycle.Append(fx.StartHook(func() error {
+				return errors.New("failed to start")
+			}))
+		}))
+		Expect(err).To(HaveOccurred())
+
+		report := recorder.Report()
+		Expect(report.Error).To(ContainSubstring("failed to start"))
+		Expect(report.Steps).To(ContainElement(And(
+			HaveField("Kind", startup.KindStart),
+			HaveField("Error", "failed to start"),
+		)))
+	})
+
+	It("writes the report to a file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "startup.json")
+		GinkgoT().Setenv("STARTUP_REPORT_FILE", file)
+
+		recorder := newRecorder()
+		Expect(start(recorder)).To(Succeed())
+
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+
+		var report startup.Report
+		Expect(json.Unmarshal(data, &report)).To(Succeed())
+		Expect(report.Steps).To(HaveLen(len(recorder.Report().Steps)))
+	})
+
+	It("fails on invalid configuration", func() {
+		GinkgoT().Setenv("STARTUP_REPORT_SLOWEST", "many")
+
+		_, err := startup.NewRecorder(zaptest.NewLogger(GinkgoT()), fxevent.NopLogger)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,84 @@
+package startup
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// KindProvide is a constructor provided to the application
+	KindProvide = "provide"
+	// KindDecorate is a decorator of a provided value
+	KindDecorate = "decorate"
+	// KindInvoke is a function invoked when the application is created
+	KindInvoke = "invoke"
+	// KindStart is an OnStart hook
+	KindStart = "start"
+)
+
+// Report describes how long it took to start the application.
+type Report struct {
+	// Began is when the application started being created
+	Began time.Time `json:"began"`
+	// Duration is the time from the application being created until it
+	// was started, zero if it has not started yet
+	Duration time.Duration `json:"durationNs"`
+	// Steps are the steps taken to start the application, in order
+	Steps []Step `json:"steps"`
+	// Error is set if the application failed to start
+	Error string `json:"error,omitempty"`
+}
+
+// Step is a constructor, invoke or hook run while starting the application.
+type Step struct {
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Module   string        `json:"module,omitempty"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"durationNs"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (s Step) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("kind", s.Kind)
+	enc.AddString("name", s.Name)
+	if s.Module != "" {
+		enc.AddString("module", s.Module)
+	}
+	enc.AddDuration("duration", s.Duration)
+	return nil
+}
+
+// Total returns the time spent in steps of the given kind.
+func (r Report) Total(kind string) time.Duration {
+	var total time.Duration
+	for _, step := range r.Steps {
+		if step.Kind == kind {
+			total += step.Duration
+		}
+	}
+	return total
+}
+
+// Slowest returns the n slowest steps. Invokes are excluded as their time
+// includes the constructors they caused to run.
+func (r Report) Slowest(n int) []Step {
+	steps := make([]Step, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		if step.Kind != KindInvoke {
+			steps = append(steps, step)
+		}
+	}
+
+	slices.SortStableFunc(steps, func(a, b Step) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+
+	if len(steps) > n {
+		steps = steps[:n]
+	}
+	return steps
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Module starts the workers registered via Provide when the application
+// starts and stops them when the application stops.
+var Module = fx.Module(
+	"sprout:worker",
+	fx.Provide(logging.Logger("worker"), fx.Private),
+	fx.Invoke(register),
+)
+
+type params struct {
+	fx.In
+
+	Lifecycle     fx.Lifecycle
+	Logger        *zap.Logger
+	Checks        health.Checks
+	MeterProvider metric.MeterProvider
+	Command       string `name:"service:command" optional:"true"`
+
+	Workers []*Definition `group:"sprout:workers"`
+}
+
+func register(p params) error {
+	if len(p.Workers) == 0 {
+		return nil
+	}
+
+	if p.Command != "" {
+		p.Logger.Info("Not starting workers while running a command", zap.String("command", p.Command))
+		return nil
+	}
+
+	metrics, err := newMetrics(p.MeterProvider.Meter("sprout/worker"))
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(p.Workers))
+	for _, definition := range p.Workers {
+		if _, exists := names[definition.Name]; exists {
+			return fmt.Errorf("worker %s registered more than once", definition.Name)
+		}
+		names[definition.Name] = struct{}{}
+
+		runner := newRunner(definition, p.Logger, metrics)
+
+		p.Checks.AddLivenessCheck(health.Check{
+			Name:  "worker:" + definition.Name,
+			Check: runner.Check,
+		})
+
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				runner.Start()
+				return nil
+			},
+			OnStop: runner.Stop,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+type heartbeatKey struct{}
+
+// Heartbeat records that the worker running with the context is alive. It
+// does nothing if the context does not belong to a worker.
+func Heartbeat(ctx context.Context) {
+	if r, ok := ctx.Value(heartbeatKey{}).(*runner); ok {
+		r.heartbeat()
+	}
+}
+
+type metrics struct {
+	runs     metric.Int64Counter
+	restarts metric.Int64Counter
+	running  metric.Int64UpDownCounter
+}
+
+func newMetrics(meter metric.Meter) (*metrics, error) {
+	runs, err := meter.Int64Counter(
+		"sprout.worker.runs",
+		metric.WithDescription("Number of times a worker has run to completion."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	restarts, err := meter.Int64Counter(
+		"sprout.worker.restarts",
+		metric.WithDescription("Number of times a worker has been restarted."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := meter.Int64UpDownCounter(
+		"sprout.worker.running",
+		metric.WithDescription("Number of workers that are currently running."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		runs:     runs,
+		restarts: restarts,
+		running:  running,
+	}, nil
+}
+
+// runner runs a single worker, restarting it according to its policy.
+type runner struct {
+	definition *Definition
+	logger     *zap.Logger
+	metrics    *metrics
+	attributes metric.MeasurementOption
+
+	lastHeartbeat atomic.Int64
+
+	mutex   sync.Mutex
+	failure error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRunner(definition *Definition, logger *zap.Logger, metrics *metrics) *runner {
+	r := &runner{
+		definition: definition,
+		logger:     logger.With(zap.String("worker", definition.Name)),
+		metrics:    metrics,
+		attributes: metric.WithAttributes(attribute.String("worker", definition.Name)),
+		done:       make(chan struct{}),
+	}
+	// Health checks may run before the worker has started
+	r.heartbeat()
+	return r
+}
+
+func (r *runner) heartbeat() {
+	r.lastHeartbeat.Store(time.Now().UnixNano())
+}
+
+// Start starts the worker in the background.
+func (r *runner) Start() {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), heartbeatKey{}, r))
+	r.cancel = cancel
+	r.heartbeat()
+
+	go func() {
+		defer close(r.done)
+		defer func() {
+			if value := recover(); value != nil {
+				crash.Report(ctx, "worker "+r.definition.Name, value, debug.Stack())
+			}
+		}()
+
+		r.loop(ctx)
+	}()
+}
+
+// Stop cancels the context of the worker and waits for it to return.
+func (r *runner) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker %s did not stop: %w", r.definition.Name, ctx.Err())
+	}
+}
+
+func (r *runner) loop(ctx context.Context) {
+	backoff := r.definition.InitialBackoff
+	restarts := 0
+
+	for {
+		r.logger.Debug("Starting worker")
+		startTime := time.Now()
+		r.heartbeat()
+
+		r.metrics.running.Add(context.Background(), 1, r.attributes)
+		err := r.definition.Run(ctx)
+		r.metrics.running.Add(context.Background(), -1, r.attributes)
+
+		if ctx.Err() != nil {
+			// Stopped as part of shutdown, errors are expected
+			r.logger.Debug("Worker stopped")
+			return
+		}
+
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		r.metrics.runs.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("worker", r.definition.Name),
+			attribute.String("result", result),
+		))
+
+		if !r.shouldRestart(err) {
+			if err != nil {
+				r.logger.Error("Worker failed, not restarting", zap.Error(err))
+				r.setFailure(err)
+			} else {
+				r.logger.Info("Worker finished")
+			}
+			return
+		}
+
+		// Reset the backoff if the worker ran for long enough to be
+		// considered healthy
+		if time.Since(startTime) > r.definition.MaxBackoff {
+			backoff = r.definition.InitialBackoff
+			restarts = 0
+		}
+
+		restarts++
+		if r.definition.MaxRestarts > 0 && restarts > r.definition.MaxRestarts {
+			r.logger.Error("Worker restarted too many times, giving up", zap.Error(err), zap.Int("restarts", restarts-1))
+			r.setFailure(fmt.Errorf("gave up after %d restarts: %w", restarts-1, err))
+			return
+		}
+
+		if err != nil {
+			r.logger.Warn("Worker failed, restarting", zap.Error(err), zap.Duration("backoff", backoff))
+		} else {
+			r.logger.Info("Worker returned, restarting", zap.Duration("backoff", backoff))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		r.metrics.restarts.Add(context.Background(), 1, r.attributes)
+		backoff = min(backoff*2, r.definition.MaxBackoff)
+	}
+}
+
+func (r *runner) shouldRestart(err error) bool {
+	switch r.definition.RestartPolicy {
+	case RestartAlways:
+		return true
+	case RestartNever:
+		return false
+	default:
+		return err != nil
+	}
+}
+
+func (r *runner) setFailure(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failure = err
+}
+
+// Check is used as the liveness check of the worker. It fails if the worker
+// has given up or if it has stopped sending heartbeats.
+func (r *runner) Check(ctx context.Context) error {
+	r.mutex.Lock()
+	failure := r.failure
+	r.mutex.Unlock()
+
+	if failure != nil {
+		return failure
+	}
+
+	timeout := r.definition.HeartbeatTimeout
+	if timeout <= 0 {
+		return nil
+	}
+
+	select {
+	case <-r.done:
+		// Workers that finished successfully no longer send heartbeats
+		return nil
+	default:
+	}
+
+	since := time.Since(time.Unix(0, r.lastHeartbeat.Load()))
+	if since > timeout {
+		return errors.New("no heartbeat for " + since.Truncate(time.Millisecond).String())
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/constructor"
+	"go.uber.org/fx"
+)
+
+// Func is the function run by a worker. It should run until the context is
+// canceled.
+type Func func(ctx context.Context) error
+
+// RestartPolicy controls when a worker is restarted after it returns.
+type RestartPolicy int
+
+const (
+	// RestartOnFailure restarts the worker if it returns an error.
+	RestartOnFailure RestartPolicy = iota
+	// RestartAlways restarts the worker whenever it returns.
+	RestartAlways
+	// RestartNever never restarts the worker.
+	RestartNever
+)
+
+// Definition describes a worker and how it is run.
+type Definition struct {
+	Name string
+	Run  Func
+
+	// RestartPolicy controls when the worker is restarted
+	RestartPolicy RestartPolicy
+	// InitialBackoff is the delay before the first restart, doubled for
+	// every consecutive restart
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between restarts
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of consecutive restarts before giving up,
+	// 0 means no limit
+	MaxRestarts int
+	// HeartbeatTimeout is how long the worker may go without calling
+	// Heartbeat before it is considered not alive, 0 disables the check
+	HeartbeatTimeout time.Duration
+}
+
+// Option configures a worker.
+type Option func(*Definition)
+
+// WithRestartPolicy sets when the worker is restarted.
+func WithRestartPolicy(policy RestartPolicy) Option {
+	return func(d *Definition) {
+		d.RestartPolicy = policy
+	}
+}
+
+// WithBackoff sets the initial and maximum delay between restarts.
+func WithBackoff(initial time.Duration, max time.Duration) Option {
+	return func(d *Definition) {
+		d.InitialBackoff = initial
+		d.MaxBackoff = max
+	}
+}
+
+// WithMaxRestarts sets the number of consecutive restarts before the worker
+// gives up and is reported as not alive.
+func WithMaxRestarts(restarts int) Option {
+	return func(d *Definition) {
+		d.MaxRestarts = restarts
+	}
+}
+
+// WithHeartbeatTimeout enables a liveness check that fails if the worker has
+// not called Heartbeat within the timeout.
+func WithHeartbeatTimeout(timeout time.Duration) Option {
+	return func(d *Definition) {
+		d.HeartbeatTimeout = timeout
+	}
+}
+
+func newDefinition(name string, run Func, options []Option) *Definition {
+	d := &Definition{
+		Name:           name,
+		Run:            run,
+		RestartPolicy:  RestartOnFailure,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+// Provide creates a constructor for use with fx.Provide that registers a
+// worker. fn is either the function to run, or a constructor that takes
+// dependencies and returns the function to run, optionally with an error.
+func Provide(name string, fn any, options ...Option) any {
+	c, err := constructor.Wrap(fn, func(run Func) *Definition {
+		return newDefinition(name, run, options)
+	})
+	if err != nil {
+		// Report the error when the application is created
+		c = func() (*Definition, error) {
+			return nil, fmt.Errorf("invalid worker %s: %w", name, err)
+		}
+	}
+
+	return fx.Annotate(c, fx.ResultTags(`group:"sprout:workers"`))
+}
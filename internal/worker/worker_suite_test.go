@@ -0,0 +1,16 @@
+package worker_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWorker(t *testing.T) {
+	// Avoid conflicts with other packages testing the health server
+	t.Setenv("HEALTH_SERVER_PORT", "8092")
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Worker Suite")
+}
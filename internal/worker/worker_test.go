@@ -0,0 +1,182 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/worker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+var _ = Describe("Worker", func() {
+	AfterEach(func() {
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	newApp := func(options ...fx.Option) *fxtest.App {
+		return fxtest.New(
+			GinkgoT(),
+			logging.Module(zaptest.NewLogger(GinkgoT())),
+			health.Module,
+			fx.Provide(func() metric.MeterProvider { return noop.NewMeterProvider() }),
+			worker.Module,
+			fx.Options(options...),
+		)
+	}
+
+	liveness := func() int {
+		res, err := http.Get("http://localhost:8092/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	It("runs until the application stops", func() {
+		started := make(chan struct{})
+		var stopped atomic.Bool
+
+		app := newApp(fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			stopped.Store(true)
+			return ctx.Err()
+		})))
+		app.RequireStart()
+
+		Eventually(started).Should(BeClosed())
+		Expect(stopped.Load()).To(BeFalse())
+
+		app.RequireStop()
+		Expect(stopped.Load()).To(BeTrue())
+	})
+
+	It("does not run while running a command", func() {
+		var ran atomic.Bool
+
+		app := newApp(
+			fx.Supply(fx.Annotated{Name: "service:command", Target: "backfill"}),
+			fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+				ran.Store(true)
+				return nil
+			})),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Consistently(ran.Load, "100ms").Should(BeFalse())
+	})
+
+	It("supports constructors with dependencies", func() {
+		var logger atomic.Pointer[zap.Logger]
+
+		app := newApp(
+			fx.Provide(logging.Logger("test")),
+			fx.Provide(worker.Provide("test", func(l *zap.Logger) (worker.Func, error) {
+				return func(ctx context.Context) error {
+					logger.Store(l)
+					<-ctx.Done()
+					return nil
+				}, nil
+			})),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(logger.Load).ShouldNot(BeNil())
+	})
+
+	It("restarts on failure", func() {
+		var runs atomic.Int32
+
+		app := newApp(fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				return errors.New("failed")
+			}
+			<-ctx.Done()
+			return nil
+		}, worker.WithBackoff(time.Millisecond, 10*time.Millisecond))))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(runs.Load).Should(Equal(int32(3)))
+		Consistently(runs.Load, 50*time.Millisecond).Should(Equal(int32(3)))
+	})
+
+	It("does not restart when returning without error", func() {
+		var runs atomic.Int32
+
+		app := newApp(fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}, worker.WithBackoff(time.Millisecond, 10*time.Millisecond))))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(runs.Load).Should(Equal(int32(1)))
+		Consistently(runs.Load, 50*time.Millisecond).Should(Equal(int32(1)))
+		Expect(liveness()).To(Equal(http.StatusOK))
+	})
+
+	It("fails liveness after giving up", func() {
+		var runs atomic.Int32
+
+		app := newApp(fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("failed")
+		}, worker.WithBackoff(time.Millisecond, 10*time.Millisecond), worker.WithMaxRestarts(2))))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(runs.Load).Should(Equal(int32(3)))
+		Eventually(liveness).WithTimeout(3 * time.Second).Should(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("fails liveness without heartbeats", func() {
+		var beating atomic.Bool
+		beating.Store(true)
+
+		app := newApp(fx.Provide(worker.Provide("test", func(ctx context.Context) error {
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					if beating.Load() {
+						worker.Heartbeat(ctx)
+					}
+				}
+			}
+		}, worker.WithHeartbeatTimeout(100*time.Millisecond))))
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(liveness()).To(Equal(http.StatusOK))
+
+		beating.Store(false)
+		Eventually(liveness).WithTimeout(3 * time.Second).Should(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("fails for invalid functions", func() {
+		app := fx.New(
+			fx.NopLogger,
+			fx.Provide(worker.Provide("test", "not a function")),
+			fx.Invoke(fx.Annotate(func([]*worker.Definition) {}, fx.ParamTags(`group:"sprout:workers"`))),
+		)
+		Expect(app.Err()).To(MatchError(ContainSubstring("invalid worker test")))
+	})
+})
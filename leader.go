@@ -0,0 +1,45 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/leader"
+	"go.uber.org/fx"
+)
+
+// Leader reports if this instance is the leader among the replicas of the
+// service, for work that must only run on one replica. The backend used for
+// the election is configured via LEADER_BACKEND, and the election only runs
+// if Leader is used.
+//
+// Example:
+//
+//	fx.Provide(sprout.Schedule("report", "@hourly", func(leader sprout.Leader) sprout.ScheduleFunc {
+//		return func(ctx context.Context) error {
+//			if !leader.IsLeader() {
+//				return nil
+//			}
+//
+//			// ...
+//		}
+//	}))
+type Leader = leader.Leadership
+
+// LeaderLock is a backend for leader election, see LeaderBackend.
+type LeaderLock = leader.Lock
+
+// LeaderBackend replaces the backend used for leader election with a custom
+// lock. The constructor can take dependencies and must return a LeaderLock.
+//
+// Example:
+//
+//	sprout.New("my-service", "1.0.0").Run(
+//		sprout.LeaderBackend(func(client *redis.Client) sprout.LeaderLock {
+//			return newRedisLock(client)
+//		}),
+//	)
+func LeaderBackend(constructor any) fx.Option {
+	return fx.Provide(fx.Annotate(
+		constructor,
+		fx.As(new(leader.Lock)),
+		fx.ResultTags(`name:"sprout:leader:lock"`),
+	))
+}
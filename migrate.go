@@ -0,0 +1,31 @@
+package sprout
+
+import (
+	"io/fs"
+
+	"github.com/aholstenson/sprout-go/internal/migrate"
+	"go.uber.org/fx"
+)
+
+// SQLMigrator applies and rolls back the migrations registered via
+// SQLMigrations.
+type SQLMigrator = migrate.Migrator
+
+// SQLMigrations returns a module that applies migrations to the database
+// provided by SQL when the application starts, before it reports as ready.
+// Migrations are read from the root of fsys and are named
+// <version>_<name>.up.sql, with an optional <version>_<name>.down.sql to roll
+// back. Replicas take a lock so that only one of them migrates at a time.
+//
+// Example:
+//
+//	//go:embed *.sql
+//	var migrations embed.FS
+//
+//	sprout.New("my-service", "1.0.0").With(
+//		sprout.SQL("pgx"),
+//		sprout.SQLMigrations(migrations),
+//	).Run()
+func SQLMigrations(fsys fs.FS) fx.Option {
+	return migrate.Module(fsys)
+}
@@ -0,0 +1,51 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/module"
+	"go.uber.org/fx"
+)
+
+// ModuleInfo describes a module created via Module. It can be injected
+// within the module.
+type ModuleInfo = module.Info
+
+// Module creates a named Fx module for a feature of the application. Within
+// the module a *zap.Logger, trace.Tracer and metric.Meter named after the
+// module are available, and ModuleConfig reads configuration using a prefix
+// derived from the name, such as USER_BILLING for user-billing.
+//
+// Modules are listed in the logs when the application is created and can be
+// disabled by setting MODULE_<PREFIX>_ENABLED to false, in which case none of
+// the options are used.
+//
+// Example:
+//
+//	var Module = sprout.Module(
+//		"billing",
+//		fx.Provide(sprout.ModuleConfig(Config{})),
+//		fx.Invoke(func(logger *zap.Logger, tracer trace.Tracer, config Config) {
+//			// ...
+//		}),
+//	)
+func Module(name string, options ...fx.Option) fx.Option {
+	return module.New(name, options...)
+}
+
+// ModuleConfig will read configuration from the environment using the
+// prefix of the module it is provided in, and provide the specified type to
+// the module. See Config.
+//
+// Example:
+//
+//	type Config struct {
+//		Currency string `env:"CURRENCY" envDefault:"EUR"`
+//	}
+//
+//	var Module = sprout.Module(
+//		"billing",
+//		// Reads BILLING_CURRENCY
+//		fx.Provide(sprout.ModuleConfig(Config{}), fx.Private),
+//	)
+func ModuleConfig[T any](value T) any {
+	return module.Config(value)
+}
@@ -0,0 +1,222 @@
+package sprout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ConfigError is returned when configuration could not be read from the
+// environment. Errors contains every problem found.
+type ConfigError = config.Error
+
+// Exit codes used by Run, following the conventions of sysexits.h.
+const (
+	// ExitCodeError is used for errors without a more specific exit code
+	ExitCodeError = 1
+	// ExitCodeUnavailable is used when a port the application listens on is
+	// already in use
+	ExitCodeUnavailable = 69
+	// ExitCodeTimeout is used when the application did not start within the
+	// start timeout
+	ExitCodeTimeout = 75
+	// ExitCodeConfig is used when the configuration is invalid
+	ExitCodeConfig = 78
+)
+
+// RunErrorReason classifies why an application failed.
+type RunErrorReason string
+
+const (
+	// RunErrorConfig is used when the configuration is invalid
+	RunErrorConfig RunErrorReason = "config"
+	// RunErrorPortInUse is used when a port is already in use
+	RunErrorPortInUse RunErrorReason = "port_in_use"
+	// RunErrorStartTimeout is used when OnStart hooks did not complete
+	// within the start timeout
+	RunErrorStartTimeout RunErrorReason = "start_timeout"
+	// RunErrorCreate is used when the application could not be created,
+	// such as when a dependency is missing or a constructor fails
+	RunErrorCreate RunErrorReason = "create"
+	// RunErrorStart is used when an OnStart hook fails
+	RunErrorStart RunErrorReason = "start"
+	// RunErrorStop is used when an OnStop hook fails
+	RunErrorStop RunErrorReason = "stop"
+	// RunErrorExit is used when the application was shut down with a
+	// non-zero exit code via fx.Shutdowner
+	RunErrorExit RunErrorReason = "exit"
+)
+
+// RunError is returned by Run when the application fails.
+type RunError struct {
+	// Reason classifies the error
+	Reason RunErrorReason
+	// Code is the exit code the process should exit with
+	Code int
+	// Err is the underlying error
+	Err error
+}
+
+func (e *RunError) Error() string {
+	return e.Message() + ": " + e.Err.Error()
+}
+
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// Message returns a human-readable description of the reason.
+func (e *RunError) Message() string {
+	switch e.Reason {
+	case RunErrorConfig:
+		return "invalid configuration"
+	case RunErrorPortInUse:
+		return "address already in use"
+	case RunErrorStartTimeout:
+		return "application did not start in time"
+	case RunErrorCreate:
+		return "could not create application"
+	case RunErrorStart:
+		return "could not start application"
+	case RunErrorStop:
+		return "could not stop application"
+	case RunErrorExit:
+		return "application exited"
+	}
+	return "application failed"
+}
+
+// ExitCode returns the exit code for an error returned by Run, 0 if err is
+// nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var runErr *RunError
+	if errors.As(err, &runErr) {
+		return runErr.Code
+	}
+	return ExitCodeError
+}
+
+// Run creates the application with the given options and runs it until it
+// is stopped via a signal or fx.Shutdowner. Unlike With(options...).Run(),
+// which exits the process on failure, errors are returned as a *RunError
+// that classifies the failure and has an exit code, see ExitCode. In
+// development mode a summary of the failure is also printed.
+//
+// Example:
+//
+//	func main() {
+//		err := sprout.New("ExampleApp", "v1.0.0").Run(
+//			example.Module,
+//		)
+//		os.Exit(sprout.ExitCode(err))
+//	}
+func (s *Sprout) Run(options ...fx.Option) error {
+	err := s.run(options)
+
+	var runErr *RunError
+	if errors.As(err, &runErr) {
+		s.logger.Error("Application failed", zap.String("reason", string(runErr.Reason)), zap.Int("exitCode", runErr.Code), zap.Error(runErr.Err))
+		if s.serviceInfo.Development {
+			printSummary(os.Stderr, runErr)
+		}
+	}
+
+	// The application is managed by Run, so telemetry is only flushed here,
+	// after the failure has been logged
+	_ = s.coordinator.Flush()
+	return err
+}
+
+func (s *Sprout) run(options []fx.Option) error {
+	app := s.with(options, true)
+	if err := app.Err(); err != nil {
+		return classify(RunErrorCreate, err)
+	}
+
+	startCtx, cancel := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		// Fx rolls back with the start context, which has expired if the
+		// start timed out, so stop hooks that did start with a new context
+		_ = stopApp(app)
+		return classify(RunErrorStart, err)
+	}
+
+	signal := <-app.Wait()
+
+	if err := stopApp(app); err != nil {
+		return classify(RunErrorStop, err)
+	}
+
+	if signal.ExitCode != 0 {
+		return &RunError{
+			Reason: RunErrorExit,
+			Code:   signal.ExitCode,
+			Err:    fmt.Errorf("shut down with exit code %d", signal.ExitCode),
+		}
+	}
+	return nil
+}
+
+func stopApp(app *fx.App) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancel()
+	return app.Stop(ctx)
+}
+
+// classify determines the reason and exit code of an error, falling back to
+// the reason of the phase the error happened in.
+func classify(phase RunErrorReason, err error) *RunError {
+	var configErr *ConfigError
+	switch {
+	case errors.As(err, &configErr):
+		return &RunError{Reason: RunErrorConfig, Code: ExitCodeConfig, Err: err}
+	case errors.Is(err, syscall.EADDRINUSE):
+		return &RunError{Reason: RunErrorPortInUse, Code: ExitCodeUnavailable, Err: err}
+	case phase == RunErrorStart && errors.Is(err, context.DeadlineExceeded):
+		return &RunError{Reason: RunErrorStartTimeout, Code: ExitCodeTimeout, Err: err}
+	}
+
+	return &RunError{Reason: phase, Code: ExitCodeError, Err: err}
+}
+
+// printSummary writes a human-readable description of a failure, intended
+// for development where the structured logs are harder to read.
+func printSummary(w io.Writer, err *RunError) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nApplication failed: %s\n\n", err.Message())
+
+	var configErr *ConfigError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err.Err, &configErr):
+		for _, e := range configErr.Errors {
+			fmt.Fprintf(&b, "  - %s\n", e)
+		}
+		b.WriteString("\nCheck the environment variables of the application.\n")
+	case err.Reason == RunErrorPortInUse && errors.As(err.Err, &opErr) && opErr.Addr != nil:
+		fmt.Fprintf(&b, "  %s is already in use by another process.\n", opErr.Addr)
+		b.WriteString("\nStop the other process or configure a different port.\n")
+	case err.Reason == RunErrorStartTimeout:
+		fmt.Fprintf(&b, "  %s\n", err.Err)
+		b.WriteString("\nAn OnStart hook is blocking, see the logs for the hook that did not complete.\n")
+	default:
+		fmt.Fprintf(&b, "  %s\n", err.Err)
+	}
+
+	fmt.Fprintf(&b, "\nExiting with code %d\n\n", err.Code)
+	_, _ = io.WriteString(w, b.String())
+}
@@ -0,0 +1,43 @@
+package sprout
+
+import (
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/schedule"
+)
+
+// ScheduleFunc is a function run by a scheduled job.
+type ScheduleFunc = schedule.Func
+
+// ScheduleOption configures a scheduled job created via Schedule.
+type ScheduleOption = schedule.Option
+
+// Schedule returns a function that can be used with fx.Provide to run a job
+// on a cron schedule while the application is running. Both standard five
+// field expressions and descriptors such as @hourly and @every 5m are
+// supported. A run is skipped if the previous run is still in progress.
+//
+// fn is either a ScheduleFunc or a constructor that takes dependencies and
+// returns a ScheduleFunc, optionally with an error.
+//
+// Example:
+//
+//	fx.Provide(sprout.Schedule("cleanup", "*/5 * * * *", func(db *sql.DB) sprout.ScheduleFunc {
+//		return func(ctx context.Context) error {
+//			// ...
+//		}
+//	}, sprout.ScheduleTimeout(time.Minute)))
+func Schedule(name string, spec string, fn any, options ...ScheduleOption) any {
+	return schedule.Provide(name, spec, fn, options...)
+}
+
+// ScheduleJitter adds a random delay of up to jitter before every run of a
+// scheduled job.
+func ScheduleJitter(jitter time.Duration) ScheduleOption {
+	return schedule.WithJitter(jitter)
+}
+
+// ScheduleTimeout limits the duration of a single run of a scheduled job.
+func ScheduleTimeout(timeout time.Duration) ScheduleOption {
+	return schedule.WithTimeout(timeout)
+}
@@ -5,9 +5,21 @@ import (
 	"os"
 
 	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/buildinfo"
+	"github.com/aholstenson/sprout-go/internal/crash"
+	"github.com/aholstenson/sprout-go/internal/diagnostics"
+	"github.com/aholstenson/sprout-go/internal/flags"
 	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/leader"
 	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/migrate"
+	"github.com/aholstenson/sprout-go/internal/module"
+	"github.com/aholstenson/sprout-go/internal/profiler"
 	"github.com/aholstenson/sprout-go/internal/runtime"
+	"github.com/aholstenson/sprout-go/internal/schedule"
+	"github.com/aholstenson/sprout-go/internal/shutdown"
+	"github.com/aholstenson/sprout-go/internal/startup"
+	"github.com/aholstenson/sprout-go/internal/worker"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
@@ -15,14 +27,25 @@ import (
 )
 
 type Sprout struct {
-	logger *zap.Logger
+	logger      *zap.Logger
+	tuner       *runtime.Tuner
+	coordinator *shutdown.Coordinator
 
 	serviceInfo internal.ServiceInfo
+	buildInfo   buildinfo.Info
+
+	commands []*CommandDefinition
 }
 
 // New creates a new Sprout application. The name and version will be used to
-// identify the application in logs, traces and metrics.
+// identify the application in logs, traces and metrics. If version is empty it
+// is derived from the build information embedded in the binary.
 func New(name string, version string) *Sprout {
+	buildInfo := buildinfo.Read()
+	if version == "" {
+		version = buildInfo.DefaultVersion()
+	}
+
 	serviceInfo := internal.ServiceInfo{
 		Name:        name,
 		Version:     version,
@@ -30,8 +53,15 @@ func New(name string, version string) *Sprout {
 		Testing:     false,
 	}
 
-	logger, err := logging.CreateRootLogger(serviceInfo)
+	coordinator, err := shutdown.New()
+	if err != nil {
+		_, _ = os.Stderr.WriteString("Unable to bootstrap: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	logger, err := logging.CreateRootLogger(serviceInfo, coordinator)
 	if err != nil {
+		_ = coordinator.Flush()
 		_, _ = os.Stderr.WriteString("Unable to bootstrap: " + err.Error() + "\n")
 		os.Exit(1)
 	}
@@ -41,29 +71,97 @@ func New(name string, version string) *Sprout {
 	slogLogger := slog.New(zapslog.NewHandler(logger.Core()))
 	slog.SetDefault(slogLogger)
 
+	// Report panics through the logger instead of only to stderr
+	reporter, err := crash.NewReporter(logger, serviceInfo, coordinator)
+	if err != nil {
+		// Fatal logs flush telemetry before exiting
+		logger.Fatal("Unable to configure crash reporting", zap.Error(err))
+	}
+	crash.SetDefault(reporter)
+
 	// Continue bootstrapping
-	logger.Info("Starting application", zap.String("name", name), zap.String("version", version))
-	runtime.Setup(logger)
+	logger.Info("Starting application", zap.String("name", name), zap.String("version", version), zap.String("revision", buildInfo.Revision))
+	tuner, err := runtime.Setup(logger)
+	if err != nil {
+		logger.Fatal("Unable to configure runtime", zap.Error(err))
+	}
+
 	return &Sprout{
 		logger:      logger,
+		tuner:       tuner,
+		coordinator: coordinator,
 		serviceInfo: serviceInfo,
+		buildInfo:   buildInfo,
 	}
 }
 
 // With lets you specify Fx options to be used when creating the application.
+//
+// If SPROUT_VALIDATE is set to true the application is validated instead,
+// exiting with 0 if it is valid and 1 otherwise. See Validate.
 func (s *Sprout) With(options ...fx.Option) *fx.App {
+	return s.with(options, false)
+}
+
+// with creates the application, validating it instead if SPROUT_VALIDATE is
+// set. See newApp for managed.
+func (s *Sprout) with(options []fx.Option, managed bool) *fx.App {
+	if internal.CheckIfValidating() {
+		os.Exit(s.validate(options))
+	}
+
+	return s.newApp(options, managed)
+}
+
+// newApp creates the application. If managed is true the caller starts and
+// stops the application and flushes telemetry once it is done logging,
+// otherwise telemetry is flushed when the application stops or fails to
+// start.
+func (s *Sprout) newApp(options []fx.Option, managed bool) *fx.App {
 	logger := s.logger
 
+	fxLogger := &fxevent.ZapLogger{
+		Logger: logging.CreateLogger(logger, []string{"fx"}),
+	}
+	recorder, err := startup.NewRecorder(logging.CreateLogger(logger, []string{"startup"}), fxLogger)
+	if err != nil {
+		return fx.New(
+			fx.WithLogger(func() fxevent.Logger { return fxLogger }),
+			fx.Error(err),
+		)
+	}
+
 	allOptions := []fx.Option{
 		fx.WithLogger(func() fxevent.Logger {
-			return &fxevent.ZapLogger{
-				Logger: logging.CreateLogger(logger, []string{"fx"}),
+			if managed {
+				return recorder
+			}
+
+			return &flushingLogger{
+				Logger:      recorder,
+				coordinator: s.coordinator,
 			}
 		}),
+		fx.Supply(recorder),
 		fx.Supply(s.serviceInfo),
+		// Included first so that telemetry is flushed after all other
+		// components have stopped
+		shutdown.Module(s.coordinator, managed),
 		logging.Module(logger),
+		runtime.Module(s.tuner),
+		buildinfo.Module(s.buildInfo),
 		otelModule,
 		health.Module,
+		diagnostics.Module,
+		profiler.Module,
+		// Included before other modules with OnStart hooks, so that
+		// migrations are applied before they start
+		migrate.Startup,
+		worker.Module,
+		schedule.Module,
+		flags.Module,
+		leader.Module,
+		module.Listing,
 	}
 
 	allOptions = append(allOptions, options...)
@@ -74,3 +172,20 @@ func (s *Sprout) With(options ...fx.Option) *fx.App {
 func enableHealthServer(checks Health) {
 	// Do nothing, only here to make health server always start
 }
+
+// flushingLogger flushes telemetry after Fx has logged that the application
+// failed to start, as (*fx.App).Run exits the process directly afterwards.
+// Flushing earlier would shut down the providers before the error is
+// exported.
+type flushingLogger struct {
+	fxevent.Logger
+	coordinator *shutdown.Coordinator
+}
+
+func (l *flushingLogger) LogEvent(event fxevent.Event) {
+	l.Logger.LogEvent(event)
+
+	if e, ok := event.(*fxevent.Started); ok && e.Err != nil {
+		_ = l.coordinator.Flush()
+	}
+}
@@ -0,0 +1,26 @@
+package sprout
+
+import (
+	"github.com/aholstenson/sprout-go/internal/sqldb"
+	"go.uber.org/fx"
+)
+
+// SQL returns a module that provides a *sql.DB opened with the named driver,
+// which must be registered with database/sql by importing it. The pool is
+// configured via environment variables prefixed with SQL_, queries are traced
+// with OpenTelemetry, pool statistics are exported as metrics, a readiness
+// check pings the database and the pool is closed when the application stops.
+//
+// Example:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	sprout.New("my-service", "1.0.0").With(
+//		sprout.SQL("pgx"),
+//		fx.Invoke(func(db *sql.DB) {
+//			// ...
+//		}),
+//	).Run()
+func SQL(driverName string) fx.Option {
+	return sqldb.Module(driverName)
+}
@@ -2,8 +2,13 @@ package test
 
 import (
 	"github.com/aholstenson/sprout-go/internal"
+	"github.com/aholstenson/sprout-go/internal/flags"
 	"github.com/aholstenson/sprout-go/internal/health"
+	"github.com/aholstenson/sprout-go/internal/leader"
 	"github.com/aholstenson/sprout-go/internal/logging"
+	"github.com/aholstenson/sprout-go/internal/migrate"
+	"github.com/aholstenson/sprout-go/internal/schedule"
+	"github.com/aholstenson/sprout-go/internal/worker"
 	"go.opentelemetry.io/otel"
 	logglobal "go.opentelemetry.io/otel/log/global"
 	lognoop "go.opentelemetry.io/otel/log/noop"
@@ -56,6 +61,11 @@ func Module(t TB) fx.Option {
 		fx.Provide(otel.GetMeterProvider),
 		fx.Provide(logglobal.GetLoggerProvider),
 		health.Module,
+		migrate.Startup,
+		worker.Module,
+		schedule.Module,
+		flags.Module,
+		leader.Module,
 	)
 }
 
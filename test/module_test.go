@@ -1,11 +1,14 @@
 package test_test
 
 import (
+	"context"
+
 	"github.com/aholstenson/sprout-go"
 	"github.com/aholstenson/sprout-go/test"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/open-feature/go-sdk/openfeature/memprovider"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
@@ -151,4 +154,73 @@ var _ = Describe("Test", func() {
 
 		Expect(health).NotTo(BeNil())
 	})
+
+	It("sprout.Flags is available", func() {
+		t := GinkgoT()
+		t.Setenv("FLAG_NEW_CHECKOUT", "true")
+
+		var flags *sprout.Flags
+		app := fxtest.New(
+			t,
+			test.Module(t),
+			fx.Populate(&flags),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(flags.Bool(context.Background(), "new-checkout", false)).To(BeTrue())
+	})
+
+	It("sprout.FlagsProvider replaces the provider", func() {
+		var flags *sprout.Flags
+		app := fxtest.New(
+			GinkgoT(),
+			test.Module(GinkgoT()),
+			sprout.FlagsProvider(func() *memprovider.InMemoryProvider {
+				provider := memprovider.NewInMemoryProvider(map[string]memprovider.InMemoryFlag{
+					"theme": {
+						State:          memprovider.Enabled,
+						DefaultVariant: "on",
+						Variants:       map[string]any{"on": "green"},
+					},
+				})
+				return &provider
+			}),
+			fx.Populate(&flags),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Expect(flags.String(context.Background(), "theme", "light")).To(Equal("green"))
+	})
+
+	It("sprout.LeaderBackend replaces the backend", func() {
+		t := GinkgoT()
+		t.Setenv("LEADER_RETRY_PERIOD", "20ms")
+		t.Setenv("LEADER_RENEW_DEADLINE", "100ms")
+
+		var leader sprout.Leader
+		app := fxtest.New(
+			t,
+			test.Module(t),
+			sprout.LeaderBackend(func() sprout.LeaderLock {
+				return alwaysLock{}
+			}),
+			fx.Populate(&leader),
+		)
+		app.RequireStart()
+		defer app.RequireStop()
+
+		Eventually(leader.IsLeader).Should(BeTrue())
+	})
 })
+
+type alwaysLock struct{}
+
+func (alwaysLock) Acquire(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (alwaysLock) Release(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package test_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/aholstenson/sprout-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+)
+
+type RunConf struct {
+	Host string `env:"HOST,required"`
+}
+
+var _ = Describe("Run", func() {
+	run := func(options ...fx.Option) error {
+		return sprout.New("run", "v1.0.0").Run(options...)
+	}
+
+	It("returns nil when shut down", func() {
+		err := run(fx.Invoke(func(lifecycle fx.Lifecycle, shutdowner fx.Shutdowner) {
+			lifecycle.Append(fx.StartHook(func() error {
+				return shutdowner.Shutdown()
+			}))
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sprout.ExitCode(err)).To(Equal(0))
+	})
+
+	It("returns the exit code given to fx.Shutdowner", func() {
+		err := run(fx.Invoke(func(lifecycle fx.Lifecycle, shutdowner fx.Shutdowner) {
+			lifecycle.Append(fx.StartHook(func() error {
+				return shutdowner.Shutdown(fx.ExitCode(3))
+			}))
+		}))
+
+		var runErr *sprout.RunError
+		Expect(err).To(BeAssignableToTypeOf(runErr))
+		Expect(err).To(HaveField("Reason", sprout.RunErrorExit))
+		Expect(sprout.ExitCode(err)).To(Equal(3))
+	})
+
+	It("classifies configuration errors", func() {
+		err := run(
+			fx.Provide(sprout.Config("RUN", RunConf{})),
+			fx.Invoke(func(RunConf) {}),
+		)
+		Expect(err).To(HaveField("Reason", sprout.RunErrorConfig))
+		Expect(sprout.ExitCode(err)).To(Equal(sprout.ExitCodeConfig))
+
+		var configErr *sprout.ConfigError
+		Expect(errors.As(err, &configErr)).To(BeTrue())
+		Expect(configErr.Prefix).To(Equal("RUN"))
+		Expect(configErr.Errors).To(HaveLen(1))
+	})
+
+	It("classifies ports already in use", func() {
+		ln, err := net.Listen("tcp", ":8098")
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		err = run()
+		Expect(err).To(HaveField("Reason", sprout.RunErrorPortInUse))
+		Expect(sprout.ExitCode(err)).To(Equal(sprout.ExitCodeUnavailable))
+	})
+
+	It("classifies start timeouts", func() {
+		err := run(
+			fx.StartTimeout(50*time.Millisecond),
+			fx.Invoke(func(lifecycle fx.Lifecycle) {
+				lifecycle.Append(fx.StartHook(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}))
+			}),
+		)
+		Expect(err).To(HaveField("Reason", sprout.RunErrorStartTimeout))
+		Expect(sprout.ExitCode(err)).To(Equal(sprout.ExitCodeTimeout))
+	})
+
+	It("classifies errors creating the application", func() {
+		err := run(fx.Invoke(func(RunConf) {}))
+		Expect(err).To(HaveField("Reason", sprout.RunErrorCreate))
+		Expect(sprout.ExitCode(err)).To(Equal(sprout.ExitCodeError))
+	})
+})
@@ -8,6 +8,9 @@ import (
 )
 
 func TestTest(t *testing.T) {
+	// Avoid conflicts with other packages testing servers
+	t.Setenv("HEALTH_SERVER_PORT", "8098")
+
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Test Suite")
 }
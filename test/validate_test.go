@@ -0,0 +1,49 @@
+package test_test
+
+import (
+	"github.com/aholstenson/sprout-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/fx"
+)
+
+type ValidatedConf struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT" envDefault:"8080"`
+}
+
+var _ = Describe("Validate", func() {
+	It("valid application has no errors", func() {
+		t := GinkgoT()
+		t.Setenv("VALIDATED_HOST", "localhost")
+
+		errs := sprout.New("validate", "v1.0.0").Validate(
+			fx.Provide(sprout.Config("VALIDATED", ValidatedConf{})),
+			fx.Invoke(func(ValidatedConf) {}),
+		)
+		Expect(errs).To(BeEmpty())
+	})
+
+	It("reports all errors at once", func() {
+		t := GinkgoT()
+		t.Setenv("VALIDATED_PORT", "invalid")
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317")
+
+		errs := sprout.New("validate", "v1.0.0").Validate(
+			fx.Provide(sprout.Config("VALIDATED", ValidatedConf{})),
+			fx.Invoke(func(ValidatedConf) {}),
+		)
+		Expect(errs).To(HaveLen(3))
+		Expect(errs[0]).To(MatchError(ContainSubstring("VALIDATED_HOST")))
+		Expect(errs[1]).To(MatchError(ContainSubstring("Port")))
+		Expect(errs[2]).To(MatchError(ContainSubstring("OTEL_EXPORTER_OTLP_ENDPOINT")))
+	})
+
+	It("reports errors building the application", func() {
+		errs := sprout.New("validate", "v1.0.0").Validate(
+			fx.Invoke(func(ValidatedConf) {}),
+		)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("missing type")))
+	})
+})
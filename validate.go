@@ -0,0 +1,53 @@
+package sprout
+
+import (
+	"slices"
+
+	"github.com/aholstenson/sprout-go/internal/config"
+	"github.com/aholstenson/sprout-go/internal/logging"
+	sproutotel "github.com/aholstenson/sprout-go/internal/otel"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Validate builds the application with the given options without starting
+// it and returns every problem found. Configuration is resolved for all
+// components used by the application, with all invalid values reported
+// instead of only the first, and the OTLP exporter settings are checked. No
+// OnStart hooks are run, so no ports are bound and no workers are started.
+//
+// Setting SPROUT_VALIDATE to true makes With validate the application and
+// exit, which lets CI and deploy pipelines catch misconfiguration before
+// rolling out.
+func (s *Sprout) Validate(options ...fx.Option) []error {
+	validation := &config.Validation{}
+	app := s.newApp(slices.Concat(options, []fx.Option{fx.Supply(validation)}), true)
+
+	errs := validation.Errors()
+	if err := app.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return append(errs, sproutotel.ValidateExporterConfig()...)
+}
+
+func (s *Sprout) validate(options []fx.Option) int {
+	logger := logging.CreateLogger(s.logger, []string{"validate"})
+
+	errs := s.Validate(options...)
+	for _, err := range errs {
+		logger.Error("Validation failed", zap.Error(err))
+	}
+
+	exitCode := 0
+	if len(errs) > 0 {
+		logger.Error("Application is invalid", zap.Int("errors", len(errs)))
+		exitCode = 1
+	} else {
+		logger.Info("Application is valid")
+	}
+
+	// Nothing has been started, but telemetry may have been buffered
+	_ = s.coordinator.Flush()
+	return exitCode
+}
@@ -0,0 +1,80 @@
+package sprout
+
+import (
+	"context"
+	"time"
+
+	"github.com/aholstenson/sprout-go/internal/worker"
+)
+
+// WorkerFunc is a function run by a worker. It should run until the context
+// is canceled, which happens when the application stops.
+type WorkerFunc = worker.Func
+
+// WorkerOption configures a worker created via Worker.
+type WorkerOption = worker.Option
+
+// WorkerRestartPolicy controls when a worker is restarted after it returns.
+type WorkerRestartPolicy = worker.RestartPolicy
+
+const (
+	// WorkerRestartOnFailure restarts the worker if it returns an error. This
+	// is the default.
+	WorkerRestartOnFailure = worker.RestartOnFailure
+	// WorkerRestartAlways restarts the worker whenever it returns.
+	WorkerRestartAlways = worker.RestartAlways
+	// WorkerRestartNever never restarts the worker.
+	WorkerRestartNever = worker.RestartNever
+)
+
+// Worker returns a function that can be used with fx.Provide to run a
+// background worker. The worker is started when the application starts, with
+// a context that is canceled when the application stops, and restarted with
+// exponential backoff according to its restart policy.
+//
+// fn is either a WorkerFunc or a constructor that takes dependencies and
+// returns a WorkerFunc, optionally with an error.
+//
+// Example:
+//
+//	fx.Provide(sprout.Worker("consumer", func(logger *zap.Logger) sprout.WorkerFunc {
+//		return func(ctx context.Context) error {
+//			for {
+//				sprout.Heartbeat(ctx)
+//				// ...
+//			}
+//		}
+//	}, sprout.WorkerHeartbeatTimeout(time.Minute)))
+func Worker(name string, fn any, options ...WorkerOption) any {
+	return worker.Provide(name, fn, options...)
+}
+
+// WorkerRestart sets when the worker is restarted.
+func WorkerRestart(policy WorkerRestartPolicy) WorkerOption {
+	return worker.WithRestartPolicy(policy)
+}
+
+// WorkerBackoff sets the initial and maximum delay between restarts of a
+// worker. The delay is doubled for every consecutive restart. Defaults to
+// 1s and 1m.
+func WorkerBackoff(initial time.Duration, max time.Duration) WorkerOption {
+	return worker.WithBackoff(initial, max)
+}
+
+// WorkerMaxRestarts sets the number of consecutive restarts before a worker
+// gives up, after which its liveness check fails.
+func WorkerMaxRestarts(restarts int) WorkerOption {
+	return worker.WithMaxRestarts(restarts)
+}
+
+// WorkerHeartbeatTimeout enables a liveness check for the worker that fails
+// if Heartbeat has not been called within the timeout.
+func WorkerHeartbeatTimeout(timeout time.Duration) WorkerOption {
+	return worker.WithHeartbeatTimeout(timeout)
+}
+
+// Heartbeat records that the worker running with the context is alive. Used
+// together with WorkerHeartbeatTimeout.
+func Heartbeat(ctx context.Context) {
+	worker.Heartbeat(ctx)
+}